@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"groundhog/internal/oplog"
+	"groundhog/internal/profile"
+	"groundhog/internal/server"
+	gtasks "groundhog/internal/tools/tasks"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joho/godotenv"
+)
+
+// importTasks runs "groundhog import-tasks [flags] FILE", which creates the
+// tasks of a Todoist, Taskwarrior or CSV export through the configured tasks
+// provider. The import is logged as one turn of the owner, so it can be
+// undone from the web app.
+func importTasks(args []string) {
+	fs := flag.NewFlagSet("import-tasks", flag.ExitOnError)
+	withCredsFile := fs.String("with-creds-file", "", "filename with json creds of the service acount")
+	format := fs.String("format", "", "todoist-csv, todoist-json, taskwarrior or csv; detected when empty")
+	list := fs.String("list", "", "task list for tasks without a project")
+	includeCompleted := fs.Bool("include-completed", false, "import completed tasks too")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported without creating anything")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: groundhog import-tasks [flags] FILE")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+	notesDir := os.Getenv("NOTES_DIR")
+	if notesDir == "" {
+		log.Fatalf("Please, provide NOTES_DIR environmnet variable")
+	}
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = filepath.Join(notesDir, ".groundhog")
+	}
+
+	provider, err := gtasks.NewProviderFromEnv(*withCredsFile, false, notesDir)
+	if err != nil {
+		log.Fatalf("Couldn't configure tasks provider: %v", err)
+	}
+	opLog, err := oplog.Open(filepath.Join(dataDir, "oplog.jsonl"))
+	if err != nil {
+		log.Fatalf("Couldn't load operation log: %v", err)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Couldn't open export: %v", err)
+	}
+	defer f.Close()
+
+	ctx := context.WithValue(context.Background(), "UserID", server.OwnerUserID)
+	ctx = profile.NewContext(ctx, profile.Profile{TimeZone: os.Getenv("DEFAULT_TIME_ZONE")})
+	ctx = oplog.WithTurn(oplog.NewContext(ctx, opLog))
+	result, err := gtasks.ImportTasks(ctx, provider, f, gtasks.ImportOptions{
+		Format:           *format,
+		List:             *list,
+		IncludeCompleted: *includeCompleted,
+		DryRun:           *dryRun,
+	})
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	for _, name := range result.NewLists {
+		fmt.Printf("new list: %s\n", name)
+	}
+	for _, t := range result.Tasks {
+		line := fmt.Sprintf("[%s] %s", t.List, t.Title)
+		if t.Parent != "" {
+			line += " (subtask of " + t.Parent + ")"
+		}
+		if t.Due != "" {
+			line += ", due " + t.Due
+		}
+		if t.Priority > 0 {
+			line += fmt.Sprintf(", priority %d", t.Priority)
+		}
+		if t.Status == "completed" {
+			line += ", completed"
+		}
+		if t.Error != "" {
+			line += " — failed: " + t.Error
+		}
+		fmt.Println(line)
+	}
+	if result.DryRun {
+		fmt.Printf("Dry run (%s): %d tasks would be created, %d skipped.\n", result.Format, len(result.Tasks), result.Skipped)
+		return
+	}
+	fmt.Printf("Imported %d tasks (%s), %d skipped, %d failed.\n", result.Created, result.Format, result.Skipped, result.Failed)
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"groundhog/internal/agent"
+	"groundhog/internal/changes"
 	"groundhog/internal/notes"
+	"groundhog/internal/oplog"
+	"groundhog/internal/profile"
+	"groundhog/internal/recurring"
 	"groundhog/internal/server"
 	gtools "groundhog/internal/tools/calendar"
+	"groundhog/internal/tools/planner"
 	gtasks "groundhog/internal/tools/tasks"
+	"groundhog/internal/tools/undo"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/tmc/langchaingo/tools"
@@ -20,6 +29,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import-tasks" {
+		importTasks(os.Args[2:])
+		return
+	}
+
 	withCredsFile := flag.String("with-creds-file", "", "filename with json creds of the service acount")
 	withOauth := flag.Bool("with-creds-oauth", false, "enable oauth authentication with the app")
 	flag.Parse()
@@ -35,21 +49,101 @@ func main() {
 		log.Fatalf("Please, provide NOTES_DIR environmnet variable")
 	}
 
-	calendarEnabled := *withCredsFile != "" || *withOauth
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = filepath.Join(notesDir, ".groundhog")
+	}
+	opLog, err := oplog.Open(filepath.Join(dataDir, "oplog.jsonl"))
+	if err != nil {
+		log.Fatalf("Couldn't load operation log: %v", err)
+	}
+
+	recurringRules, err := recurring.Open(filepath.Join(dataDir, "recurring.json"))
+	if err != nil {
+		log.Fatalf("Couldn't load recurring tasks: %v", err)
+	}
+
+	travelPolicy, err := gtools.LoadTravelPolicy(filepath.Join(dataDir, "travel.json"))
+	if err != nil {
+		log.Fatalf("Couldn't load travel policy: %v", err)
+	}
+
+	googleEnabled := *withCredsFile != "" || *withOauth
+	calendarProvider, err := gtools.NewProviderFromEnv(*withCredsFile, *withOauth)
+	if err != nil {
+		log.Fatalf("Couldn't configure calendar provider: %v", err)
+	}
+
+	if calendarProvider != nil {
+		calendarProvider = gtools.NewCachedProvider(calendarProvider)
+		opLog.Register("calendar", gtools.NewReverter(calendarProvider))
+	}
+	if googleEnabled {
+		opLog.Register("tasks", gtasks.NewReverter(*withCredsFile))
+	}
+
 	availableTools := []tools.Tool{
 		tools.Calculator{},
 		notes.NewTool(notesDir, 5),
+		changes.Propose(undo.NewUndoLastChange(opLog)),
+	}
+	taskProvider, err := gtasks.NewProviderFromEnv(*withCredsFile, *withOauth, notesDir)
+	if err != nil {
+		log.Fatalf("Couldn't configure tasks provider: %v", err)
+	}
+	listTasks := gtasks.NewListTasks(taskProvider)
+	availableTools = append(
+		availableTools,
+		listTasks,
+		changes.Propose(gtasks.NewAddTask(taskProvider)),
+	)
+	recurringTasks := gtasks.NewRecurringSync(taskProvider, recurringRules)
+	availableTools = append(
+		availableTools,
+		changes.Propose(gtasks.NewUpdateTask(taskProvider)),
+		changes.Propose(gtasks.NewMoveTask(taskProvider)),
+		changes.Confirm(gtasks.NewDeleteTask(taskProvider)),
+		gtasks.NewListRecurring(recurringRules),
+		changes.Propose(gtasks.NewManageRecurring(taskProvider, recurringRules)),
+	)
+	// Managing lists and clearing completed tasks only work with Google
+	// Tasks.
+	if _, googleTasks := taskProvider.(*gtasks.GoogleTaskProvider); googleTasks {
+		availableTools = append(
+			availableTools,
+			gtasks.NewListTaskLists(*withCredsFile),
+			changes.Propose(gtasks.NewManageTaskList(*withCredsFile)),
+			changes.Confirm(gtasks.NewClearCompleted(*withCredsFile)),
+		)
+	}
+	if markdownTasks, ok := taskProvider.(*gtasks.MarkdownTaskProvider); ok {
+		opLog.Register("markdown", gtasks.NewMarkdownReverter(markdownTasks))
 	}
-	if calendarEnabled {
+	if calendarProvider != nil {
 		availableTools = append(
 			availableTools,
-			gtools.NewListEvent(*withCredsFile),
-			gtools.NewAddEvent(*withCredsFile),
-			gtools.NewEditEvent(*withCredsFile),
-			gtasks.NewListTasks(*withCredsFile),
-			gtasks.NewAddTask(*withCredsFile),
+			gtools.NewListEvent(calendarProvider),
+			gtools.NewGetEvent(calendarProvider),
+			gtools.NewListInvitations(calendarProvider),
+			changes.Propose(gtools.NewAddEvent(calendarProvider, travelPolicy)),
+			changes.Propose(gtools.NewEditEvent(calendarProvider, travelPolicy)),
+			changes.Propose(gtools.NewShiftEvents(calendarProvider, travelPolicy)),
+			changes.Propose(gtools.NewRespondToInvitation(calendarProvider)),
+			planner.NewScheduleTasks(calendarProvider, listTasks),
 		)
 	}
+	// Triage only needs a calendar to reschedule into.
+	var triageAddEvent tools.Tool
+	if calendarProvider != nil {
+		triageAddEvent = gtools.NewAddEvent(calendarProvider, travelPolicy)
+	}
+	availableTools = append(availableTools, planner.NewTriageTasks(
+		calendarProvider,
+		listTasks,
+		triageAddEvent,
+		gtasks.NewUpdateTask(taskProvider),
+		gtasks.NewDeleteTask(taskProvider),
+	))
 
 	agentExecutor := agent.NewAgent(availableTools)
 
@@ -72,6 +166,8 @@ func main() {
 			ClientSecret: googleSecret,
 			RedirectURL:  googleRedirectUrl,
 			Scopes: []string{
+				"openid",
+				"email",
 				"https://www.googleapis.com/auth/calendar",
 				"https://www.googleapis.com/auth/tasks",
 			},
@@ -79,7 +175,31 @@ func main() {
 		}
 	}
 
-	server := server.New(agentExecutor, oauthConfig)
+	profiles, err := profile.NewStore(filepath.Join(dataDir, "profiles.json"), os.Getenv("DEFAULT_TIME_ZONE"))
+	if err != nil {
+		log.Fatalf("Couldn't load user profiles: %v", err)
+	}
+
+	if !*withOauth {
+		// The service account works without a signed-in user, so due
+		// recurring tasks are created even when nobody is chatting.
+		go func() {
+			for ; ; time.Sleep(time.Hour) {
+				ctx := context.WithValue(context.Background(), "UserID", server.OwnerUserID)
+				if _, err := recurringTasks.Sync(profile.NewContext(ctx, profiles.Get(server.OwnerUserID))); err != nil {
+					log.Println("Couldn't sync recurring tasks:", err)
+				}
+			}
+		}()
+	}
+
+	// Without a background run, OAuth users' recurring tasks are created
+	// when they connect.
+	var connectSync *gtasks.RecurringSync
+	if *withOauth {
+		connectSync = recurringTasks
+	}
+	server := server.New(agentExecutor, oauthConfig, profiles, opLog, connectSync)
 	port := 8080
 	log.Printf("Server starting on http://localhost:%d\n", port)
 	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), server); err != nil {
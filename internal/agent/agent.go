@@ -1,10 +1,11 @@
 package agent
 
 import (
-	"fmt"
 	"log"
 	"time"
 
+	"groundhog/internal/profile"
+
 	"github.com/tmc/langchaingo/agents"
 	"github.com/tmc/langchaingo/llms/openai"
 	"github.com/tmc/langchaingo/prompts"
@@ -13,7 +14,7 @@ import (
 
 // NewAgent creates a new langchaingo agent that uses native tool calling so the
 // model can invoke tools like calendar or calculator without hitting tool_choice errors.
-func NewAgent(tools []langchainTools.Tool) (*agents.Executor) {
+func NewAgent(tools []langchainTools.Tool) *agents.Executor {
 	llm, err := openai.New(
 		openai.WithBaseURL("https://api.groq.com/openai/v1"),
 		openai.WithModel("openai/gpt-oss-20b"),
@@ -27,10 +28,9 @@ func NewAgent(tools []langchainTools.Tool) (*agents.Executor) {
 		prompts.NewGenericMessagePromptTemplate("Chat history", "{{ .history }}", []string{"history"}),
 	}
 
-	tn := time.Now()
-	now := tn.Format(time.RFC822)
-
-	systemMessage := fmt.Sprintf(`You are the Groundhog assistant. Current date and time is %s. Help users manage schedules and tasks using the provided tools. Default to tool use whenever information must be fetched, created, or updated instead of inventing details. Keep answers brief and actionable.  When asked to edit a calendar event, first obtain the event ID via the calendar list tool before attempting any update. When asked to add calendar event, first check that given event doesn't exists already in calendar`, now)
+	// The current time and the user's time zone are filled in per call from
+	// the "now", "time_zone" and "availability" inputs, see PromptInputs.
+	systemMessage := `You are the Groundhog assistant. Current date and time is {{.now}} (user time zone: {{.time_zone}}). Help users manage schedules and tasks using the provided tools. Default to tool use whenever information must be fetched, created, or updated instead of inventing details. Keep answers brief and actionable.  When asked to edit a calendar event, first obtain the event ID via the calendar list tool before attempting any update. When asked to add calendar event, first check that given event doesn't exists already in calendar. When the user wants to prepare for a meeting or asks who is attending, fetch it with calendar_get_event. For invitations, list them with calendar_list_invitations and answer with calendar_respond_invitation; it refuses to accept over conflicts, so only pass ignore_conflicts when the user explicitly asks. To find tasks or review what the user finished, use the filters of the tasks tool (query, due_min, due_max, completed_min) instead of listing everything. To rename, reschedule, complete or reopen a task, get its id from the tasks list and use tasks_update. To break a big goal into ordered steps, create the steps as subtasks with tasks_add (parent and previous) and reorder them with tasks_move. For routines that repeat (a weekly review, paying rent) use tasks_recurring rather than adding single tasks; GroundHog creates each next task itself. When the user asks to clean up overdue or forgotten tasks, call tasks_triage; if they disagree with a decision, call it again with an override for that task. When planning a day, call schedule_tasks to get the time blocks instead of choosing times yourself, then add the blocks the user accepts with calendar_add_event. When plans change during the day (something new comes up or the user runs late), use calendar_shift_events to move the remaining events instead of editing them one by one. Interpret and state times in the user's time zone. User availability: {{.availability}}. Keep new events inside it; the add and edit tools refuse bookings that break it, so only pass ignore_availability when the user explicitly asks for that time. When a tool answers that a change was proposed, tell the user it is waiting for their approval instead of claiming it is done.`
 
 	baseAgent := agents.NewOpenAIFunctionsAgent(
 		llm,
@@ -41,3 +41,19 @@ func NewAgent(tools []langchainTools.Tool) (*agents.Executor) {
 	myAgent := &OpenAIParametriesedFunctionsAgent{OpenAIFunctionsAgent: baseAgent}
 	return agents.NewExecutor(myAgent, agents.WithMaxIterations(10))
 }
+
+// PromptInputs returns the per-call values the system prompt expects, rendered
+// in the user's time zone.
+func PromptInputs(p profile.Profile) map[string]any {
+	loc := p.Location()
+	now := time.Now().In(loc)
+	zone := loc.String()
+	if loc == time.Local {
+		zone = now.Format("MST (UTC-07:00)")
+	}
+	return map[string]any{
+		"now":          now.Format("Monday, 02 Jan 2006 15:04 MST"),
+		"time_zone":    zone,
+		"availability": p.Availability.Summary(),
+	}
+}
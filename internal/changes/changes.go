@@ -0,0 +1,190 @@
+// Package changes lets mutating tools propose their work instead of doing it.
+// A tool wrapped with Propose records its call in the Set found in the
+// context; the user later applies or rejects each recorded change and only
+// applied changes reach the calendar or task list.
+package changes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApplied  = "applied"
+	StatusRejected = "rejected"
+	StatusFailed   = "failed"
+
+	statusApplying = "applying"
+)
+
+// Change is one proposed tool call.
+type Change struct {
+	ID      string `json:"id"`
+	Tool    string `json:"tool"`
+	Summary string `json:"summary"`
+	Input   string `json:"input"`
+	Status  string `json:"status"`
+	// Result holds the tool output once applied, or the error if it failed.
+	Result string `json:"result,omitempty"`
+
+	tool tools.Tool
+}
+
+// Set collects the changes proposed over one conversation.
+type Set struct {
+	mu      sync.Mutex
+	nextID  int
+	changes []*Change
+}
+
+func NewSet() *Set {
+	return &Set{}
+}
+
+// Add records a pending call of tool with input. Tools that propose several
+// changes at once use it directly; most go through Propose.
+func (s *Set) Add(tool tools.Tool, summary, input string) Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	c := &Change{
+		ID:      strconv.Itoa(s.nextID),
+		Tool:    tool.Name(),
+		Summary: summary,
+		Input:   input,
+		Status:  StatusPending,
+		tool:    tool,
+	}
+	s.changes = append(s.changes, c)
+	return *c
+}
+
+// Pending returns the changes waiting for a decision, oldest first.
+func (s *Set) Pending() []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Change
+	for _, c := range s.changes {
+		if c.Status == StatusPending {
+			res = append(res, *c)
+		}
+	}
+	return res
+}
+
+// Apply runs the change with id. A failed run is recorded on the change and
+// also returned.
+func (s *Set) Apply(ctx context.Context, id string) (Change, error) {
+	c, err := s.take(id)
+	if err != nil {
+		return Change{}, err
+	}
+
+	result, err := c.tool.Call(ctx, c.Input)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		c.Status, c.Result = StatusFailed, err.Error()
+		return *c, fmt.Errorf("unable to apply change %s: %w", id, err)
+	}
+	c.Status, c.Result = StatusApplied, result
+	return *c, nil
+}
+
+// Reject drops the change with id without running it.
+func (s *Set) Reject(id string) (Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.find(id)
+	if err != nil {
+		return Change{}, err
+	}
+	c.Status = StatusRejected
+	return *c, nil
+}
+
+// ApplyAll applies every pending change in the order it was proposed and
+// keeps going after failures.
+func (s *Set) ApplyAll(ctx context.Context) []Change {
+	var res []Change
+	for _, c := range s.Pending() {
+		applied, err := s.Apply(ctx, c.ID)
+		if err != nil && applied.ID == "" {
+			continue
+		}
+		res = append(res, applied)
+	}
+	return res
+}
+
+// RejectAll rejects every pending change.
+func (s *Set) RejectAll() []Change {
+	var res []Change
+	for _, c := range s.Pending() {
+		if rejected, err := s.Reject(c.ID); err == nil {
+			res = append(res, rejected)
+		}
+	}
+	return res
+}
+
+// take marks a pending change as being applied so it can't run twice.
+func (s *Set) take(id string) (*Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	c.Status = statusApplying
+	return c, nil
+}
+
+// find returns the pending change with id. The caller holds s.mu.
+func (s *Set) find(id string) (*Change, error) {
+	for _, c := range s.changes {
+		if c.ID != id {
+			continue
+		}
+		if c.Status != StatusPending {
+			return nil, fmt.Errorf("change %s is already %s", id, c.Status)
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown change %s", id)
+}
+
+// NewContext returns a copy of ctx in which wrapped tools propose into s.
+// Tools wrapped with Propose only do so once WithReview is applied too.
+func NewContext(ctx context.Context, s *Set) context.Context {
+	return context.WithValue(ctx, "ChangeSet", s)
+}
+
+// WithReview marks ctx as belonging to a user who reviews every change.
+func WithReview(ctx context.Context) context.Context {
+	return context.WithValue(ctx, "ReviewChanges", true)
+}
+
+// Reviewing reports whether WithReview was applied to ctx.
+func Reviewing(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	review, _ := ctx.Value("ReviewChanges").(bool)
+	return review
+}
+
+// FromContext returns the Set attached by NewContext, or nil.
+func FromContext(ctx context.Context) *Set {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value("ChangeSet").(*Set)
+	return s
+}
@@ -0,0 +1,118 @@
+package changes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Describer is implemented by tools that can validate an input and summarise
+// what it would do without doing it.
+type Describer interface {
+	Describe(ctx context.Context, input string) (string, error)
+}
+
+// Resolver is implemented by tools whose input may hold relative times such
+// as "in 2 hours" or a default of now. Resolve returns the input with those
+// made absolute, so the change applies what the proposal showed however
+// late the user applies it.
+type Resolver interface {
+	Resolve(ctx context.Context, input string) (string, error)
+}
+
+// DryRunner is implemented by tools whose input can ask for a dry run. Dry
+// runs change nothing, so they run at once instead of being proposed.
+type DryRunner interface {
+	DryRun(input string) bool
+}
+
+// RefusedError is returned by Describe when the tool would refuse the call.
+// The reason is handed to the agent as the tool's answer and nothing is
+// proposed.
+type RefusedError struct {
+	Reason string
+}
+
+func (e *RefusedError) Error() string {
+	return e.Reason
+}
+
+// Refuse wraps reason in a RefusedError.
+func Refuse(reason string) error {
+	return &RefusedError{Reason: reason}
+}
+
+type parameterizedTool interface {
+	Parameters() map[string]interface{}
+}
+
+// ProposingTool wraps a mutating tool. When the user reviews changes the call
+// is recorded in the context's Set instead of executed; otherwise it runs as
+// usual.
+type ProposingTool struct {
+	tools.Tool
+
+	// always proposes even when the user doesn't review changes.
+	always bool
+}
+
+var _ tools.Tool = &ProposingTool{}
+
+func Propose(tool tools.Tool) *ProposingTool {
+	return &ProposingTool{
+		Tool: tool,
+	}
+}
+
+// Confirm wraps a destructive tool whose calls are always proposed, so the
+// user confirms each one whatever their review setting.
+func Confirm(tool tools.Tool) *ProposingTool {
+	return &ProposingTool{
+		Tool:   tool,
+		always: true,
+	}
+}
+
+// Parameters forwards the wrapped tool's schema so tool calling is unchanged.
+func (p *ProposingTool) Parameters() map[string]interface{} {
+	if pt, ok := p.Tool.(parameterizedTool); ok {
+		return pt.Parameters()
+	}
+	return nil
+}
+
+func (p *ProposingTool) Call(ctx context.Context, input string) (string, error) {
+	set := FromContext(ctx)
+	if set == nil || (!p.always && !Reviewing(ctx)) {
+		return p.Tool.Call(ctx, input)
+	}
+
+	if d, ok := p.Tool.(DryRunner); ok && d.DryRun(input) {
+		return p.Tool.Call(ctx, input)
+	}
+	if r, ok := p.Tool.(Resolver); ok {
+		resolved, err := r.Resolve(ctx, input)
+		if err != nil {
+			return "", err
+		}
+		input = resolved
+	}
+
+	summary := fmt.Sprintf("%s %s", p.Name(), input)
+	if d, ok := p.Tool.(Describer); ok {
+		var err error
+		summary, err = d.Describe(ctx, input)
+		var refused *RefusedError
+		if errors.As(err, &refused) {
+			return refused.Reason, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	c := set.Add(p.Tool, summary, input)
+	return fmt.Sprintf("Proposed change %s: %s\nNothing has been changed yet. The user reviews proposed changes and applies or rejects them; do not repeat this call.", c.ID, summary), nil
+}
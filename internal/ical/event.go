@@ -0,0 +1,272 @@
+package ical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+// Attendee is an ATTENDEE or ORGANIZER of an event.
+type Attendee struct {
+	Email    string
+	Name     string
+	PartStat string
+	Role     string
+}
+
+// Event is the typed view of a VEVENT component.
+type Event struct {
+	UID          string
+	RecurrenceID string
+	Summary      string
+	Description  string
+	Location     string
+	Status       string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	TimeZone     string
+	Organizer    *Attendee
+	Attendees    []Attendee
+}
+
+// ParseEvent reads a VEVENT. Floating times are interpreted in loc.
+func ParseEvent(c *Component, loc *time.Location) (Event, error) {
+	if c == nil || c.Name != "VEVENT" {
+		return Event{}, fmt.Errorf("component is not a VEVENT")
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	e := Event{
+		UID:          c.Value("UID"),
+		RecurrenceID: c.Value("RECURRENCE-ID"),
+		Summary:      UnescapeText(c.Value("SUMMARY")),
+		Description:  UnescapeText(c.Value("DESCRIPTION")),
+		Location:     UnescapeText(c.Value("LOCATION")),
+		Status:       strings.ToUpper(c.Value("STATUS")),
+		URL:          c.Value("URL"),
+	}
+
+	startProp, ok := c.Get("DTSTART")
+	if !ok {
+		return Event{}, fmt.Errorf("event %q has no DTSTART", e.UID)
+	}
+	start, allDay, err := ParseDateTime(startProp, loc)
+	if err != nil {
+		return Event{}, fmt.Errorf("event %q: invalid DTSTART: %w", e.UID, err)
+	}
+	e.Start, e.AllDay = start, allDay
+	e.TimeZone = startProp.Param("TZID")
+
+	switch {
+	case hasProp(c, "DTEND"):
+		endProp, _ := c.Get("DTEND")
+		end, _, err := ParseDateTime(endProp, loc)
+		if err != nil {
+			return Event{}, fmt.Errorf("event %q: invalid DTEND: %w", e.UID, err)
+		}
+		e.End = end
+	case hasProp(c, "DURATION"):
+		d, err := ParseDuration(c.Value("DURATION"))
+		if err != nil {
+			return Event{}, fmt.Errorf("event %q: invalid DURATION: %w", e.UID, err)
+		}
+		e.End = e.Start.Add(d)
+	case allDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+
+	if p, ok := c.Get("ORGANIZER"); ok {
+		o := parseAttendee(p)
+		e.Organizer = &o
+	}
+	for _, p := range c.All("ATTENDEE") {
+		e.Attendees = append(e.Attendees, parseAttendee(p))
+	}
+	return e, nil
+}
+
+// Apply writes the fields of e into c, keeping every property Event does not
+// model (alarms, recurrence rules, vendor extensions).
+func (e Event) Apply(c *Component) {
+	if e.UID != "" {
+		c.Set(Property{Name: "UID", Value: e.UID})
+	}
+	setText(c, "SUMMARY", e.Summary)
+	setText(c, "DESCRIPTION", e.Description)
+	setText(c, "LOCATION", e.Location)
+	if e.Status != "" {
+		c.Set(Property{Name: "STATUS", Value: strings.ToUpper(e.Status)})
+	}
+
+	c.Set(FormatDateTime("DTSTART", e.Start, e.AllDay))
+	c.Del("DURATION")
+	c.Set(FormatDateTime("DTEND", e.End, e.AllDay))
+	c.Set(Property{Name: "DTSTAMP", Value: time.Now().UTC().Format(dateTimeLayout + "Z")})
+
+	// Parameters Attendee doesn't model, such as RSVP or CUTYPE, are kept
+	// for the participants that stay.
+	existing := make(map[string]Property)
+	for _, p := range append(c.All("ORGANIZER"), c.All("ATTENDEE")...) {
+		existing[p.Name+":"+strings.ToLower(parseAttendee(p).Email)] = p
+	}
+	c.Del("ORGANIZER")
+	if e.Organizer != nil {
+		old := existing["ORGANIZER:"+strings.ToLower(e.Organizer.Email)]
+		c.Props = append(c.Props, formatAttendee("ORGANIZER", *e.Organizer, old))
+	}
+	c.Del("ATTENDEE")
+	for _, a := range e.Attendees {
+		old := existing["ATTENDEE:"+strings.ToLower(a.Email)]
+		c.Props = append(c.Props, formatAttendee("ATTENDEE", a, old))
+	}
+}
+
+// NewEvent builds a fresh VEVENT component from e.
+func NewEvent(e Event) *Component {
+	c := &Component{Name: "VEVENT"}
+	e.Apply(c)
+	return c
+}
+
+// ParseDateTime parses a DATE or DATE-TIME property value. The boolean result
+// reports whether the value is a DATE (all-day).
+func ParseDateTime(p Property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.Value)
+	if strings.EqualFold(p.Param("VALUE"), "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeLayout+"Z", value)
+		return t, false, err
+	}
+	if tzid := p.Param("TZID"); tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	return t, false, err
+}
+
+// FormatDateTime returns a DATE property for all-day values and a UTC
+// DATE-TIME otherwise, which needs no VTIMEZONE definition.
+func FormatDateTime(name string, t time.Time, allDay bool) Property {
+	if allDay {
+		return Property{Name: name, Params: map[string]string{"VALUE": "DATE"}, Value: t.Format(dateLayout)}
+	}
+	return Property{Name: name, Value: t.UTC().Format(dateTimeLayout + "Z")}
+}
+
+// ParseDuration parses an RFC 5545 duration such as PT1H30M, P1D or -PT15M.
+func ParseDuration(s string) (time.Duration, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign = -1
+		value = value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("malformed duration %q", s)
+	}
+	value = value[1:]
+
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, r := range value {
+		switch {
+		case r == 'T':
+			inTime = true
+		case r >= '0' && r <= '9':
+			num += string(r)
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("malformed duration %q", s)
+			}
+			num = ""
+			switch {
+			case r == 'W' && !inTime:
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				total += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("malformed duration %q", s)
+			}
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("malformed duration %q", s)
+	}
+	return sign * total, nil
+}
+
+func hasProp(c *Component, name string) bool {
+	_, ok := c.Get(name)
+	return ok
+}
+
+func setText(c *Component, name, value string) {
+	if value == "" {
+		c.Del(name)
+		return
+	}
+	c.Set(Property{Name: name, Value: EscapeText(value)})
+}
+
+func parseAttendee(p Property) Attendee {
+	email := p.Value
+	if len(email) > len("mailto:") && strings.EqualFold(email[:len("mailto:")], "mailto:") {
+		email = email[len("mailto:"):]
+	}
+	return Attendee{
+		Email:    email,
+		Name:     p.Param("CN"),
+		PartStat: strings.ToUpper(p.Param("PARTSTAT")),
+		Role:     strings.ToUpper(p.Param("ROLE")),
+	}
+}
+
+// formatAttendee writes a over old, the line it replaces; fields of a that
+// are empty keep the value old has.
+func formatAttendee(name string, a Attendee, old Property) Property {
+	p := Property{Name: name, Value: "mailto:" + a.Email, Params: map[string]string{}}
+	if old.Value != "" {
+		p.Value = old.Value
+	}
+	for k, v := range old.Params {
+		p.Params[k] = v
+	}
+	if a.Name != "" {
+		p.Params["CN"] = a.Name
+	}
+	if a.PartStat != "" && name == "ATTENDEE" {
+		p.Params["PARTSTAT"] = a.PartStat
+	}
+	if a.Role != "" && name == "ATTENDEE" {
+		p.Params["ROLE"] = a.Role
+	}
+	return p
+}
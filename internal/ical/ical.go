@@ -0,0 +1,315 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) GroundHog
+// needs: calendars made of VEVENT components. Unknown properties and nested
+// components are kept so that edited events round-trip without losing data.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Property is a single content line, e.g. DTSTART;TZID=Europe/Berlin:20251209T100000.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param returns the value of a property parameter, or "" when it is not set.
+func (p Property) Param(name string) string {
+	if p.Params == nil {
+		return ""
+	}
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Component is a BEGIN/END block such as VCALENDAR, VEVENT or VALARM.
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []*Component
+}
+
+// NewCalendar returns a VCALENDAR wrapping the given components.
+func NewCalendar(children ...*Component) *Component {
+	return &Component{
+		Name: "VCALENDAR",
+		Props: []Property{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: "-//GroundHog//GroundHog//EN"},
+			{Name: "CALSCALE", Value: "GREGORIAN"},
+		},
+		Components: children,
+	}
+}
+
+// Get returns the first property with the given name.
+func (c *Component) Get(name string) (Property, bool) {
+	name = strings.ToUpper(name)
+	for _, p := range c.Props {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Value returns the value of the first property with the given name.
+func (c *Component) Value(name string) string {
+	p, _ := c.Get(name)
+	return p.Value
+}
+
+// All returns every property with the given name, e.g. all ATTENDEEs.
+func (c *Component) All(name string) []Property {
+	name = strings.ToUpper(name)
+	var res []Property
+	for _, p := range c.Props {
+		if p.Name == name {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// Set replaces every property with the same name by p.
+func (c *Component) Set(p Property) {
+	p.Name = strings.ToUpper(p.Name)
+	c.Del(p.Name)
+	c.Props = append(c.Props, p)
+}
+
+// Del removes every property with the given name.
+func (c *Component) Del(name string) {
+	name = strings.ToUpper(name)
+	props := c.Props[:0]
+	for _, p := range c.Props {
+		if p.Name != name {
+			props = append(props, p)
+		}
+	}
+	c.Props = props
+}
+
+// Children returns the direct child components with the given name.
+func (c *Component) Children(name string) []*Component {
+	name = strings.ToUpper(name)
+	var res []*Component
+	for _, child := range c.Components {
+		if child.Name == name {
+			res = append(res, child)
+		}
+	}
+	return res
+}
+
+// Decode parses an iCalendar stream and returns its top-level components,
+// normally a single VCALENDAR.
+func Decode(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []*Component
+	var stack []*Component
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch prop.Name {
+		case "BEGIN":
+			c := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			} else {
+				roots = append(roots, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property %s outside of a component", i+1, prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Props = append(current.Props, prop)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	return roots, nil
+}
+
+// Encode writes components as iCalendar text with CRLF line endings and lines
+// folded at 75 octets.
+func Encode(w io.Writer, components ...*Component) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range components {
+		if err := encodeComponent(bw, c); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Props {
+		if err := writeLine(w, formatProperty(p)); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Components {
+		if err := encodeComponent(w, child); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+func formatProperty(p Property) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(p.Name))
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := p.Params[k]
+		if strings.ContainsAny(v, ";:,") {
+			v = `"` + v + `"`
+		}
+		b.WriteString(";" + strings.ToUpper(k) + "=" + v)
+	}
+	b.WriteString(":")
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+// writeLine folds the line at 75 octets without splitting UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) error {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read calendar data: %w", err)
+	}
+	return lines, nil
+}
+
+func parseLine(line string) (Property, error) {
+	// The name and parameters end at the first colon that is not inside a
+	// quoted parameter value.
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitParams(head)
+	prop := Property{Name: strings.ToUpper(strings.TrimSpace(parts[0])), Value: value}
+	if prop.Name == "" {
+		return Property{}, fmt.Errorf("malformed content line %q", line)
+	}
+	for _, part := range parts[1:] {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return prop, nil
+}
+
+func splitParams(head string) []string {
+	var parts []string
+	inQuotes := false
+	last := 0
+	for i, r := range head {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ';' && !inQuotes:
+			parts = append(parts, head[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, head[last:])
+}
+
+// EscapeText escapes a TEXT value (RFC 5545 section 3.3.11).
+func EscapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,630 @@
+// Package nldate parses the relative date and time expressions people (and
+// LLMs) write, such as "tomorrow 3pm", "in 1.5 hours", "next Friday morning"
+// or "end of month". Parsing is deterministic: every expression is resolved
+// against the supplied current time and its location.
+//
+// Rules worth knowing:
+//   - A bare weekday ("friday", "this friday") is the next such day on or
+//     after today; "next friday" is the Friday of next week (weeks start on
+//     Monday) and "last friday" the latest Friday before today.
+//   - A bare hour from 1 to 7 ("at 3") is in the afternoon; add am to mean
+//     the morning.
+//   - "morning", "afternoon", "evening" and "night" mean 09:00, 14:00, 18:00
+//     and 21:00; "tonight" is 20:00 and "end of day" is 17:00.
+//   - "end of week" is Friday, "next week" is next Monday and "next month"
+//     is the first day of next month.
+//   - Expressions without a time of day are reported as date-only.
+package nldate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a\.m\.|p\.m\.|h)?$`)
+	isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	ordinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+	numberPattern  = regexp.MustCompile(`^\d+(\.\d+)?$`)
+	compactPattern = regexp.MustCompile(`^(?:\d+(?:\.\d+)?(?:mins|min|m|hrs|hr|h|d|w))+$`)
+	compactPart    = regexp.MustCompile(`(\d+(?:\.\d+)?)(mins|min|m|hrs|hr|h|d|w)`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var dayParts = map[string]clock{
+	"morning":   {9, 0},
+	"noon":      {12, 0},
+	"midday":    {12, 0},
+	"afternoon": {14, 0},
+	"evening":   {18, 0},
+	"night":     {21, 0},
+	"tonight":   {20, 0},
+	"midnight":  {0, 0},
+	"eod":       {17, 0},
+}
+
+var fillers = map[string]bool{
+	"at": true, "on": true, "the": true, "by": true, "of": true, "and": true, "o'clock": true,
+}
+
+type clock struct {
+	hour, minute int
+}
+
+// state collects what the tokens said before the result is assembled.
+type state struct {
+	date     *time.Time
+	clock    *clock
+	offset   time.Duration
+	days     int
+	months   int
+	relative bool
+}
+
+// Parse resolves input relative to now. It returns the resulting time in
+// now's location and whether the expression named only a date.
+func Parse(input string, now time.Time) (time.Time, bool, error) {
+	tokens := tokenize(input)
+	if len(tokens) == 0 {
+		return time.Time{}, false, fmt.Errorf("time value is empty")
+	}
+
+	today := midnight(now)
+	var st state
+	for i := 0; i < len(tokens); {
+		n, err := st.consume(tokens, i, now, today)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if n == 0 {
+			return time.Time{}, false, fmt.Errorf("could not understand %q in %q", tokens[i], input)
+		}
+		i += n
+	}
+	return st.resolve(now, today)
+}
+
+func (st *state) consume(tokens []string, i int, now, today time.Time) (int, error) {
+	tok := tokens[i]
+	next := func(k int) string {
+		if i+k < len(tokens) {
+			return tokens[i+k]
+		}
+		return ""
+	}
+
+	switch {
+	case fillers[tok]:
+		return 1, nil
+	case tok == "now" || tok == "right" && next(1) == "now":
+		st.relative = true
+		if tok == "right" {
+			return 2, nil
+		}
+		return 1, nil
+	case tok == "today":
+		return 1, st.setDate(today)
+	case tok == "tonight":
+		c := dayParts[tok]
+		st.clock = &c
+		return 1, st.setDate(today)
+	case tok == "tomorrow" || tok == "tmrw" || tok == "tmr":
+		return 1, st.setDate(today.AddDate(0, 0, 1))
+	case tok == "yesterday":
+		return 1, st.setDate(today.AddDate(0, 0, -1))
+	case tok == "day" && next(1) == "after" && next(2) == "tomorrow":
+		return 3, st.setDate(today.AddDate(0, 0, 2))
+	case tok == "in" && (next(1) == "the" || isDayPart(next(1))):
+		// "in the morning" is a time of day, not a duration.
+		return 1, nil
+	case tok == "in":
+		d, days, months, n, ok := parseDuration(tokens[i+1:])
+		if !ok {
+			return 0, fmt.Errorf("expected a duration after \"in\"")
+		}
+		st.addOffset(d, days, months)
+		return n + 1, nil
+	case tok == "end" || tok == "eow" || tok == "eom" || tok == "eoy":
+		return st.parseEndOf(tokens, i, today)
+	case tok == "start" || tok == "beginning":
+		return st.parseStartOf(tokens, i, today)
+	case tok == "this" || tok == "next" || tok == "last":
+		return st.parseQualified(tok, next(1), today)
+	}
+
+	if wd, ok := weekdays[tok]; ok {
+		return 1, st.setDate(upcoming(today, wd))
+	}
+	if c, ok := dayParts[tok]; ok {
+		if st.clock != nil && tok != "tonight" {
+			return 0, fmt.Errorf("time of day given twice")
+		}
+		st.clock = &c
+		return 1, nil
+	}
+	if m := isoDatePattern.FindStringSubmatch(tok); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return 1, st.setDate(safeDate(y, time.Month(mo), d, now.Location()))
+	}
+	if mo, ok := months[tok]; ok {
+		return st.parseMonthDay(tokens, i, mo, today)
+	}
+	if m := ordinalPattern.FindStringSubmatch(tok); m != nil && months[next(1)] != 0 {
+		mo := months[next(1)]
+		day, _ := strconv.Atoi(m[1])
+		n := 2
+		year := today.Year()
+		if y, err := strconv.Atoi(next(2)); err == nil && y > 1900 {
+			year = y
+			n = 3
+		} else if t := safeDate(year, mo, day, today.Location()); t.Before(today) {
+			year++
+		}
+		return n, st.setDate(safeDate(year, mo, day, today.Location()))
+	}
+
+	// Durations followed by "from now", "later" or "ago".
+	if d, days, months, n, ok := parseDuration(tokens[i:]); ok {
+		switch {
+		case next(n) == "from" && next(n+1) == "now":
+			st.addOffset(d, days, months)
+			return n + 2, nil
+		case next(n) == "later":
+			st.addOffset(d, days, months)
+			return n + 1, nil
+		case next(n) == "ago":
+			st.addOffset(-d, -days, -months)
+			return n + 1, nil
+		}
+	}
+
+	if c, n, ok := parseClock(tokens, i); ok {
+		if st.clock != nil {
+			return 0, fmt.Errorf("time of day given twice")
+		}
+		st.clock = &c
+		return n, nil
+	}
+	return 0, nil
+}
+
+func isDayPart(tok string) bool {
+	_, ok := dayParts[tok]
+	return ok
+}
+
+func (st *state) setDate(t time.Time) error {
+	if st.date != nil && !st.date.Equal(t) {
+		return fmt.Errorf("date given twice")
+	}
+	st.date = &t
+	return nil
+}
+
+func (st *state) addOffset(d time.Duration, days, months int) {
+	st.relative = true
+	st.offset += d
+	st.days += days
+	st.months += months
+}
+
+func (st *state) parseQualified(qualifier, word string, today time.Time) (int, error) {
+	if wd, ok := weekdays[word]; ok {
+		switch qualifier {
+		case "this":
+			return 2, st.setDate(upcoming(today, wd))
+		case "next":
+			return 2, st.setDate(weekStart(today).AddDate(0, 0, 7+mondayIndex(wd)))
+		default:
+			// The latest wd before today, a week ago when today is wd.
+			back := (int(today.Weekday())-int(wd)+6)%7 + 1
+			return 2, st.setDate(today.AddDate(0, 0, -back))
+		}
+	}
+
+	step := map[string]int{"this": 0, "next": 1, "last": -1}[qualifier]
+	switch word {
+	case "week":
+		if step == 0 {
+			return 2, st.setDate(today)
+		}
+		return 2, st.setDate(weekStart(today).AddDate(0, 0, 7*step))
+	case "weekend":
+		sat := upcoming(today, time.Saturday)
+		if today.Weekday() == time.Sunday {
+			sat = today.AddDate(0, 0, -1)
+		}
+		return 2, st.setDate(sat.AddDate(0, 0, 7*step))
+	case "month":
+		if step == 0 {
+			return 2, st.setDate(today)
+		}
+		return 2, st.setDate(time.Date(today.Year(), today.Month()+time.Month(step), 1, 0, 0, 0, 0, today.Location()))
+	case "year":
+		if step == 0 {
+			return 2, st.setDate(today)
+		}
+		return 2, st.setDate(time.Date(today.Year()+step, time.January, 1, 0, 0, 0, 0, today.Location()))
+	}
+	if c, ok := dayParts[word]; ok && qualifier == "this" {
+		st.clock = &c
+		return 2, st.setDate(today)
+	}
+	return 0, fmt.Errorf("could not understand %q", qualifier+" "+word)
+}
+
+// parseEndOf handles "end of day/week/month/year", optionally qualified with
+// "this" or "next", and the eod/eow/eom/eoy abbreviations.
+func (st *state) parseEndOf(tokens []string, i int, today time.Time) (int, error) {
+	n := 1
+	unit := tokens[i]
+	switch unit {
+	case "eow":
+		unit = "week"
+	case "eom":
+		unit = "month"
+	case "eoy":
+		unit = "year"
+	default:
+		j := i + 1
+		for j < len(tokens) && (tokens[j] == "of" || tokens[j] == "the") {
+			j++
+		}
+		if j >= len(tokens) {
+			return 0, fmt.Errorf("expected day, week, month or year after \"end of\"")
+		}
+		unit = tokens[j]
+		n = j - i + 1
+	}
+
+	step := 0
+	if unit == "next" || unit == "this" {
+		if unit == "next" {
+			step = 1
+		}
+		if i+n >= len(tokens) {
+			return 0, fmt.Errorf("expected week, month or year after %q", unit)
+		}
+		unit = tokens[i+n]
+		n++
+	}
+
+	switch unit {
+	case "day":
+		c := dayParts["eod"]
+		st.clock = &c
+		return n, st.setDate(today.AddDate(0, 0, step))
+	case "week":
+		return n, st.setDate(weekStart(today).AddDate(0, 0, 7*step+4))
+	case "month":
+		first := time.Date(today.Year(), today.Month()+time.Month(step)+1, 1, 0, 0, 0, 0, today.Location())
+		return n, st.setDate(first.AddDate(0, 0, -1))
+	case "year":
+		return n, st.setDate(time.Date(today.Year()+step, time.December, 31, 0, 0, 0, 0, today.Location()))
+	}
+	return 0, fmt.Errorf("expected day, week, month or year after \"end of\"")
+}
+
+// parseStartOf handles "start/beginning of (next) week/month/year".
+func (st *state) parseStartOf(tokens []string, i int, today time.Time) (int, error) {
+	j := i + 1
+	for j < len(tokens) && (tokens[j] == "of" || tokens[j] == "the") {
+		j++
+	}
+	step := 0
+	if j < len(tokens) && (tokens[j] == "next" || tokens[j] == "this") {
+		if tokens[j] == "next" {
+			step = 1
+		}
+		j++
+	}
+	if j >= len(tokens) {
+		return 0, fmt.Errorf("expected week, month or year after %q", tokens[i])
+	}
+	n := j - i + 1
+	switch tokens[j] {
+	case "week":
+		return n, st.setDate(weekStart(today).AddDate(0, 0, 7*step))
+	case "month":
+		return n, st.setDate(time.Date(today.Year(), today.Month()+time.Month(step), 1, 0, 0, 0, 0, today.Location()))
+	case "year":
+		return n, st.setDate(time.Date(today.Year()+step, time.January, 1, 0, 0, 0, 0, today.Location()))
+	case "day":
+		c := dayParts["morning"]
+		st.clock = &c
+		return n, st.setDate(today)
+	}
+	return 0, fmt.Errorf("expected week, month or year after %q", tokens[i])
+}
+
+// parseMonthDay handles "december 10", "dec 10th 2025" and a bare month name.
+func (st *state) parseMonthDay(tokens []string, i int, mo time.Month, today time.Time) (int, error) {
+	n := 1
+	day := 1
+	if i+1 < len(tokens) {
+		if m := ordinalPattern.FindStringSubmatch(tokens[i+1]); m != nil {
+			day, _ = strconv.Atoi(m[1])
+			n = 2
+		}
+	}
+	year := today.Year()
+	if i+n < len(tokens) {
+		if y, err := strconv.Atoi(tokens[i+n]); err == nil && y > 1900 {
+			year = y
+			n++
+			return n, st.setDate(safeDate(year, mo, day, today.Location()))
+		}
+	}
+	if t := safeDate(year, mo, day, today.Location()); t.Before(today) {
+		year++
+	}
+	return n, st.setDate(safeDate(year, mo, day, today.Location()))
+}
+
+func (st *state) resolve(now, today time.Time) (time.Time, bool, error) {
+	if st.offset != 0 {
+		if st.date != nil || st.clock != nil {
+			return time.Time{}, false, fmt.Errorf("a relative time in hours or minutes cannot be combined with a date or time of day")
+		}
+		return now.AddDate(0, st.months, st.days).Add(st.offset), false, nil
+	}
+
+	base := today
+	if st.date != nil {
+		base = *st.date
+	}
+	base = base.AddDate(0, st.months, st.days)
+
+	switch {
+	case st.clock != nil:
+		return time.Date(base.Year(), base.Month(), base.Day(), st.clock.hour, st.clock.minute, 0, 0, now.Location()), false, nil
+	case st.date == nil && st.days == 0 && st.months == 0 && st.relative:
+		return now, false, nil
+	default:
+		return base, true, nil
+	}
+}
+
+// parseDuration reads phrases like "1.5 hours", "an hour and a half",
+// "half an hour", "2 hours 30 minutes" or "3 days". Hours and minutes are
+// returned as a duration; days, weeks and months separately so that they
+// follow the calendar.
+func parseDuration(tokens []string) (time.Duration, int, int, int, bool) {
+	var d time.Duration
+	var days, months int
+	i := 0
+	found := false
+	for i < len(tokens) {
+		if found && tokens[i] == "and" && i+1 < len(tokens) {
+			if tokens[i+1] == "a" && i+2 < len(tokens) && tokens[i+2] == "half" {
+				// "an hour and a half" / "a day and a half"
+				if last := lastUnit(tokens[:i]); last != "" {
+					d += unitDuration(last) / 2
+					i += 3
+					continue
+				}
+			}
+			i++
+			continue
+		}
+
+		var amount float64
+		n := 0
+		switch tok := tokens[i]; {
+		case tok == "a" || tok == "an" || tok == "one":
+			amount, n = 1, 1
+		case tok == "half" && i+1 < len(tokens) && (tokens[i+1] == "an" || tokens[i+1] == "a"):
+			amount, n = 0.5, 2
+		case tok == "half":
+			amount, n = 0.5, 1
+		case tok == "couple" || tok == "few":
+			amount, n = 2, 1
+			if tok == "few" {
+				amount = 3
+			}
+			if i+1 < len(tokens) && tokens[i+1] == "of" {
+				n = 2
+			}
+		case numberPattern.MatchString(tok):
+			amount, _ = strconv.ParseFloat(tok, 64)
+			n = 1
+		case compactPattern.MatchString(tok):
+			// "90m", "1h30m"
+			for _, m := range compactPart.FindAllStringSubmatch(tok, -1) {
+				amount, _ = strconv.ParseFloat(m[1], 64)
+				if !addUnit(m[2], amount, &d, &days, &months) {
+					return 0, 0, 0, 0, false
+				}
+			}
+			found = true
+			i++
+			continue
+		default:
+			if found {
+				return d, days, months, i, true
+			}
+			return 0, 0, 0, 0, false
+		}
+		if i+n >= len(tokens) || !addUnit(tokens[i+n], amount, &d, &days, &months) {
+			if found {
+				return d, days, months, i, true
+			}
+			return 0, 0, 0, 0, false
+		}
+		found = true
+		i += n + 1
+	}
+	return d, days, months, i, found
+}
+
+func addUnit(unit string, amount float64, d *time.Duration, days, months *int) bool {
+	switch unit {
+	case "m", "min", "mins", "minute", "minutes":
+		*d += time.Duration(amount * float64(time.Minute))
+	case "h", "hr", "hrs", "hour", "hours":
+		*d += time.Duration(amount * float64(time.Hour))
+	case "d", "day", "days":
+		if amount != float64(int(amount)) {
+			*d += time.Duration(amount * 24 * float64(time.Hour))
+			return true
+		}
+		*days += int(amount)
+	case "w", "week", "weeks":
+		if amount != float64(int(amount)) {
+			*d += time.Duration(amount * 7 * 24 * float64(time.Hour))
+			return true
+		}
+		*days += 7 * int(amount)
+	case "month", "months":
+		if amount != float64(int(amount)) {
+			return false
+		}
+		*months += int(amount)
+	default:
+		return false
+	}
+	return true
+}
+
+func lastUnit(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	switch last := tokens[len(tokens)-1]; last {
+	case "hour", "hours", "minute", "minutes", "day", "days":
+		return last
+	}
+	return ""
+}
+
+func unitDuration(unit string) time.Duration {
+	switch unit {
+	case "minute", "minutes":
+		return time.Minute
+	case "day", "days":
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// parseClock reads "3pm", "3 pm", "3:30pm", "15:00", "15h" or "at 3".
+func parseClock(tokens []string, i int) (clock, int, bool) {
+	tok := tokens[i]
+	n := 1
+	if i+1 < len(tokens) {
+		switch tokens[i+1] {
+		case "am", "pm", "a.m.", "p.m.":
+			tok += tokens[i+1]
+			n = 2
+		}
+	}
+	m := clockPattern.FindStringSubmatch(tok)
+	if m == nil {
+		return clock{}, 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	suffix := strings.ReplaceAll(m[3], ".", "")
+	// A lone number is only a time when it is clearly one ("at 3", "3 o'clock").
+	if m[2] == "" && suffix == "" {
+		prevAt := i > 0 && tokens[i-1] == "at"
+		nextOClock := i+1 < len(tokens) && tokens[i+1] == "o'clock"
+		if !prevAt && !nextOClock {
+			return clock{}, 0, false
+		}
+		// Nobody means 3 in the morning by "at 3".
+		if hour >= 1 && hour <= 7 {
+			hour += 12
+		}
+	}
+	switch suffix {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return clock{}, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return clock{}, 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return clock{}, 0, false
+	}
+	return clock{hour, minute}, n, true
+}
+
+func tokenize(input string) []string {
+	s := strings.ToLower(strings.TrimSpace(input))
+	s = strings.NewReplacer(",", " ", ";", " ", "!", " ", "?", " ").Replace(s)
+	return strings.Fields(s)
+}
+
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func safeDate(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	last := t.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	if day < 1 {
+		day = 1
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// upcoming returns the next wd on or after day.
+func upcoming(day time.Time, wd time.Weekday) time.Time {
+	diff := (int(wd) - int(day.Weekday()) + 7) % 7
+	return day.AddDate(0, 0, diff)
+}
+
+// weekStart returns the Monday of day's week.
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -mondayIndex(day.Weekday()))
+}
+
+func mondayIndex(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
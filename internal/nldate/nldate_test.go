@@ -0,0 +1,82 @@
+package nldate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, loc)
+	}
+	// Wednesday.
+	wednesday := date(time.December, 10, 10, 30)
+
+	tests := []struct {
+		input    string
+		now      time.Time
+		want     time.Time
+		dateOnly bool
+	}{
+		{"tomorrow 3pm", wednesday, date(time.December, 11, 15, 0), false},
+		{"tomorrow at 9", wednesday, date(time.December, 11, 9, 0), false},
+		{"at 3", wednesday, date(time.December, 10, 15, 0), false},
+		{"3 o'clock", wednesday, date(time.December, 10, 15, 0), false},
+		{"at 3am", wednesday, date(time.December, 10, 3, 0), false},
+		{"at 12", wednesday, date(time.December, 10, 12, 0), false},
+		{"15:45", wednesday, date(time.December, 10, 15, 45), false},
+		{"in 1h30m", wednesday, date(time.December, 10, 12, 0), false},
+		{"in 90m", wednesday, date(time.December, 10, 12, 0), false},
+		{"in 1.5 hours", wednesday, date(time.December, 10, 12, 0), false},
+		{"in an hour and a half", wednesday, date(time.December, 10, 12, 0), false},
+		{"2 hours from now", wednesday, date(time.December, 10, 12, 30), false},
+		{"now", wednesday, wednesday, false},
+		{"tonight", wednesday, date(time.December, 10, 20, 0), false},
+		{"next friday morning", wednesday, date(time.December, 19, 9, 0), false},
+		{"friday", wednesday, date(time.December, 12, 0, 0), true},
+		{"this wednesday", wednesday, date(time.December, 10, 0, 0), true},
+		{"next friday", wednesday, date(time.December, 19, 0, 0), true},
+		{"last friday", wednesday, date(time.December, 5, 0, 0), true},
+		{"last friday", date(time.December, 13, 12, 0), date(time.December, 12, 0, 0), true},
+		{"last friday", date(time.December, 14, 12, 0), date(time.December, 12, 0, 0), true},
+		{"last friday", date(time.December, 12, 12, 0), date(time.December, 5, 0, 0), true},
+		{"2 days ago", wednesday, date(time.December, 8, 0, 0), true},
+		{"end of week", wednesday, date(time.December, 12, 0, 0), true},
+		{"end of month", wednesday, date(time.December, 31, 0, 0), true},
+		{"end of day", wednesday, date(time.December, 10, 17, 0), false},
+		{"next week", wednesday, date(time.December, 15, 0, 0), true},
+		{"dec 24th", wednesday, date(time.December, 24, 0, 0), true},
+		{"2025-12-01", wednesday, date(time.December, 1, 0, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input+" on "+tt.now.Weekday().String(), func(t *testing.T) {
+			got, dateOnly, err := Parse(tt.input, tt.now)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) || dateOnly != tt.dateOnly {
+				t.Errorf("Parse(%q) = %s, %v; want %s, %v", tt.input, got, dateOnly, tt.want, tt.dateOnly)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	now := time.Date(2025, time.December, 10, 10, 30, 0, 0, time.UTC)
+	for _, input := range []string{
+		"",
+		"in",
+		"someday",
+		"tomorrow at 3 in 2 hours",
+		"friday saturday",
+		"at 25",
+	} {
+		if got, _, err := Parse(input, now); err == nil {
+			t.Errorf("Parse(%q) = %s; want an error", input, got)
+		}
+	}
+}
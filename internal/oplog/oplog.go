@@ -0,0 +1,303 @@
+// Package oplog keeps an append-only log of the changes tools make to the
+// user's calendar and tasks. Each entry stores the state before and after the
+// change so it can be reverted later. Reverting appends an undo entry; nothing
+// in the log is ever rewritten.
+package oplog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KindUndo marks entries that record the revert of an earlier operation.
+const KindUndo = "undo"
+
+// Operation is one entry of the log.
+type Operation struct {
+	ID     string    `json:"id"`
+	UserID string    `json:"user_id"`
+	TurnID string    `json:"turn_id,omitempty"`
+	Time   time.Time `json:"time"`
+	// Kind names the change, e.g. "calendar.create". The prefix before the
+	// dot selects the Reverter.
+	Kind    string          `json:"kind"`
+	Target  string          `json:"target,omitempty"`
+	Summary string          `json:"summary"`
+	Before  json.RawMessage `json:"before,omitempty"`
+	After   json.RawMessage `json:"after,omitempty"`
+	// Undoes is set on undo entries.
+	Undoes string `json:"undoes,omitempty"`
+}
+
+// Reverter restores the state recorded in Before for the kinds it handles.
+type Reverter interface {
+	Revert(ctx context.Context, op Operation) error
+}
+
+// Log is the operation log of all users, persisted as JSON lines.
+type Log struct {
+	path string
+
+	mu        sync.Mutex
+	ops       []Operation
+	undone    map[string]bool
+	reverters map[string]Reverter
+}
+
+// Open loads the log at path, creating it on first write.
+func Open(path string) (*Log, error) {
+	l := &Log{
+		path:      path,
+		undone:    make(map[string]bool),
+		reverters: make(map[string]Reverter),
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open operation log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var op Operation
+		if err := json.Unmarshal([]byte(line), &op); err != nil {
+			return nil, fmt.Errorf("couldn't parse operation log %s: %w", path, err)
+		}
+		l.index(op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read operation log: %w", err)
+	}
+	return l, nil
+}
+
+// Register makes r responsible for the operations whose kind starts with
+// prefix followed by a dot.
+func (l *Log) Register(prefix string, r Reverter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reverters[prefix] = r
+}
+
+// Append stores op, filling in the id, time, user and turn from ctx.
+func (l *Log) Append(ctx context.Context, op Operation) (Operation, error) {
+	if op.ID == "" {
+		op.ID = newID()
+	}
+	if op.Time.IsZero() {
+		op.Time = time.Now()
+	}
+	if op.UserID == "" {
+		op.UserID = userID(ctx)
+	}
+	if op.TurnID == "" {
+		op.TurnID = TurnID(ctx)
+	}
+
+	line, err := json.Marshal(op)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return Operation{}, fmt.Errorf("couldn't create operation log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return Operation{}, fmt.Errorf("couldn't open operation log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return Operation{}, fmt.Errorf("couldn't write operation log: %w", err)
+	}
+	l.index(op)
+	return op, nil
+}
+
+// Last returns the operations the next undo would revert for the current
+// user, newest first. With wholeTurn it returns every remaining operation of
+// the turn the newest operation belongs to.
+func (l *Log) Last(ctx context.Context, wholeTurn bool) []Operation {
+	uid := userID(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var res []Operation
+	for i := len(l.ops) - 1; i >= 0; i-- {
+		op := l.ops[i]
+		if op.UserID != uid || op.Kind == KindUndo || l.undone[op.ID] {
+			continue
+		}
+		if len(res) == 0 {
+			res = append(res, op)
+			if !wholeTurn || op.TurnID == "" {
+				return res
+			}
+			continue
+		}
+		if op.TurnID == res[0].TurnID {
+			res = append(res, op)
+		}
+	}
+	return res
+}
+
+// Get returns the operations with the given ids, in that order, when they
+// belong to the current user and haven't been undone.
+func (l *Log) Get(ctx context.Context, ids []string) ([]Operation, error) {
+	uid := userID(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	byID := make(map[string]Operation, len(ids))
+	for _, op := range l.ops {
+		if op.UserID == uid && op.Kind != KindUndo {
+			byID[op.ID] = op
+		}
+	}
+	res := make([]Operation, 0, len(ids))
+	for _, id := range ids {
+		op, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation %q", id)
+		}
+		if l.undone[id] {
+			return nil, fmt.Errorf("%q was undone already", op.Summary)
+		}
+		res = append(res, op)
+	}
+	return res, nil
+}
+
+// Undo reverts ops in the given order and records an undo entry for each one
+// that succeeded. It stops at the first failure.
+func (l *Log) Undo(ctx context.Context, ops []Operation) ([]Operation, error) {
+	var reverted []Operation
+	for _, op := range ops {
+		l.mu.Lock()
+		prefix, _, _ := strings.Cut(op.Kind, ".")
+		r, ok := l.reverters[prefix]
+		l.mu.Unlock()
+		if !ok {
+			return reverted, fmt.Errorf("don't know how to undo %s", op.Kind)
+		}
+
+		if err := r.Revert(ctx, op); err != nil {
+			return reverted, fmt.Errorf("unable to undo %q: %w", op.Summary, err)
+		}
+		if _, err := l.Append(ctx, Operation{
+			Kind:    KindUndo,
+			Target:  op.Target,
+			Summary: "Undo: " + op.Summary,
+			Undoes:  op.ID,
+		}); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, op)
+	}
+	return reverted, nil
+}
+
+// index adds op to the in-memory view. The caller holds l.mu or owns l.
+func (l *Log) index(op Operation) {
+	l.ops = append(l.ops, op)
+	if op.Kind == KindUndo {
+		l.undone[op.Undoes] = true
+	}
+}
+
+// Record appends an operation to the log attached to ctx. Before and after are
+// stored as JSON; either may be nil. Without a log in ctx it does nothing, so
+// tools keep working when the log is not configured.
+func Record(ctx context.Context, kind, target, summary string, before, after any) error {
+	l := FromContext(ctx)
+	if l == nil {
+		return nil
+	}
+	op := Operation{
+		Kind:    kind,
+		Target:  target,
+		Summary: summary,
+	}
+	var err error
+	if before != nil {
+		if op.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if op.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	_, err = l.Append(ctx, op)
+	return err
+}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Log) context.Context {
+	return context.WithValue(ctx, "OperationLog", l)
+}
+
+// FromContext returns the Log attached by NewContext, or nil.
+func FromContext(ctx context.Context) *Log {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value("OperationLog").(*Log)
+	return l
+}
+
+// WithTurn returns a copy of ctx in which recorded operations share a new
+// turn id, so they can be undone together.
+func WithTurn(ctx context.Context) context.Context {
+	return context.WithValue(ctx, "TurnID", newID())
+}
+
+// TurnID returns the turn set by WithTurn, or "".
+func TurnID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value("TurnID").(string)
+	return id
+}
+
+func userID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value("UserID").(string)
+	return id
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,254 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimeRange is a daily window in "HH:MM" clock times. An End before Start
+// wraps past midnight, as sleep usually does.
+type TimeRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// NoMeetingBlock keeps meetings out of a window, e.g. for focus time. Days
+// are lower-case weekday names; empty means every day.
+type NoMeetingBlock struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// Availability describes when the user can be booked. Every part is
+// optional; an empty Availability allows everything.
+type Availability struct {
+	// WorkingHours is keyed by lower-case weekday name. Once any day is set,
+	// missing days count as days off.
+	WorkingHours map[string]TimeRange `json:"working_hours,omitempty"`
+	NoMeetings   []NoMeetingBlock     `json:"no_meetings,omitempty"`
+	Lunch        *TimeRange           `json:"lunch,omitempty"`
+	Sleep        *TimeRange           `json:"sleep,omitempty"`
+}
+
+// Window is a concrete time range produced from the availability rules.
+type Window struct {
+	Start time.Time
+	End   time.Time
+	Label string
+}
+
+var weekdayNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// Validate checks weekday names and clock times.
+func (a Availability) Validate() error {
+	for day, r := range a.WorkingHours {
+		if !isWeekday(day) {
+			return fmt.Errorf("unknown weekday %q in working_hours", day)
+		}
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("working_hours %s: %w", day, err)
+		}
+	}
+	for _, b := range a.NoMeetings {
+		for _, day := range b.Days {
+			if !isWeekday(day) {
+				return fmt.Errorf("unknown weekday %q in no_meetings", day)
+			}
+		}
+		if err := (TimeRange{Start: b.Start, End: b.End}).validate(); err != nil {
+			return fmt.Errorf("no_meetings: %w", err)
+		}
+	}
+	if a.Lunch != nil {
+		if err := a.Lunch.validate(); err != nil {
+			return fmt.Errorf("lunch: %w", err)
+		}
+	}
+	if a.Sleep != nil {
+		if err := a.Sleep.validate(); err != nil {
+			return fmt.Errorf("sleep: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether no availability rule is configured.
+func (a Availability) IsZero() bool {
+	return len(a.WorkingHours) == 0 && len(a.NoMeetings) == 0 && a.Lunch == nil && a.Sleep == nil
+}
+
+// Working returns the working window of day, which must be midnight in the
+// user's location. ok is false on days off and when no hours are configured.
+func (a Availability) Working(day time.Time) (Window, bool) {
+	r, found := a.WorkingHours[weekdayNames[day.Weekday()]]
+	if !found {
+		return Window{}, false
+	}
+	start, end := r.on(day)
+	return Window{Start: start, End: end, Label: "working hours"}, true
+}
+
+// Blocked returns the windows of day that should stay free: lunch, sleep and,
+// for meetings, the no-meeting blocks. Windows that started the evening
+// before are included.
+func (a Availability) Blocked(day time.Time, meeting bool) []Window {
+	var res []Window
+	if a.Lunch != nil {
+		start, end := a.Lunch.on(day)
+		res = append(res, Window{Start: start, End: end, Label: "lunch"})
+	}
+	if a.Sleep != nil {
+		for _, d := range []time.Time{day.AddDate(0, 0, -1), day} {
+			start, end := a.Sleep.on(d)
+			res = append(res, Window{Start: start, End: end, Label: "sleep"})
+		}
+	}
+	if meeting {
+		name := weekdayNames[day.Weekday()]
+		for _, b := range a.NoMeetings {
+			if len(b.Days) > 0 && !containsDay(b.Days, name) {
+				continue
+			}
+			start, end := (TimeRange{Start: b.Start, End: b.End}).on(day)
+			res = append(res, Window{Start: start, End: end, Label: "no-meeting block"})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Start.Before(res[j].Start) })
+	return res
+}
+
+// Violations lists the rules that an event from start to end would break,
+// evaluated in loc. meeting enables the no-meeting blocks.
+func (a Availability) Violations(start, end time.Time, loc *time.Location, meeting bool) []string {
+	if a.IsZero() || !end.After(start) {
+		return nil
+	}
+
+	var res []string
+	seen := make(map[string]bool)
+	add := func(msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			res = append(res, msg)
+		}
+	}
+
+	s := start.In(loc)
+	for day := time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		if !start.Before(dayEnd) {
+			continue
+		}
+		if len(a.WorkingHours) > 0 {
+			w, ok := a.Working(day)
+			name := weekdayNames[day.Weekday()]
+			switch {
+			case !ok:
+				add(fmt.Sprintf("%s is a day off", capitalize(name)))
+			case start.Before(w.Start) || end.After(w.End):
+				add(fmt.Sprintf("outside working hours (%s %s–%s)", name, w.Start.Format("15:04"), w.End.Format("15:04")))
+			}
+		}
+		for _, b := range a.Blocked(day, meeting) {
+			if start.Before(b.End) && b.Start.Before(end) {
+				add(fmt.Sprintf("overlaps %s (%s–%s)", b.Label, b.Start.Format("15:04"), b.End.Format("15:04")))
+			}
+		}
+	}
+	return res
+}
+
+// Summary describes the availability in one line for the system prompt.
+func (a Availability) Summary() string {
+	if a.IsZero() {
+		return "not configured"
+	}
+
+	var parts []string
+	if len(a.WorkingHours) > 0 {
+		var days []string
+		for _, name := range append(weekdayNames[1:], weekdayNames[0]) {
+			if r, ok := a.WorkingHours[name]; ok {
+				days = append(days, fmt.Sprintf("%s %s–%s", name[:3], r.Start, r.End))
+			}
+		}
+		parts = append(parts, "working hours "+strings.Join(days, ", ")+" (other days off)")
+	}
+	for _, b := range a.NoMeetings {
+		days := "daily"
+		if len(b.Days) > 0 {
+			days = strings.Join(b.Days, "/")
+		}
+		parts = append(parts, fmt.Sprintf("no meetings %s %s–%s", days, b.Start, b.End))
+	}
+	if a.Lunch != nil {
+		parts = append(parts, fmt.Sprintf("lunch %s–%s", a.Lunch.Start, a.Lunch.End))
+	}
+	if a.Sleep != nil {
+		parts = append(parts, fmt.Sprintf("sleep %s–%s", a.Sleep.Start, a.Sleep.End))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (r TimeRange) validate() error {
+	start, err := parseClock(r.Start)
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(r.End)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("start and end must differ")
+	}
+	return nil
+}
+
+// on returns the range on day; ranges that wrap end on the next day.
+func (r TimeRange) on(day time.Time) (time.Time, time.Time) {
+	startMin, _ := parseClock(r.Start)
+	endMin, _ := parseClock(r.End)
+	start := time.Date(day.Year(), day.Month(), day.Day(), startMin/60, startMin%60, 0, 0, day.Location())
+	end := time.Date(day.Year(), day.Month(), day.Day(), endMin/60, endMin%60, 0, 0, day.Location())
+	if endMin <= startMin {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// parseClock returns minutes after midnight; "24:00" is allowed as an end.
+func parseClock(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q; use HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func isWeekday(name string) bool {
+	return containsDay(weekdayNames, name)
+}
+
+func containsDay(days []string, name string) bool {
+	for _, d := range days {
+		if strings.EqualFold(d, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
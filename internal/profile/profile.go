@@ -0,0 +1,188 @@
+// Package profile keeps per-user settings such as the user's time zone. The
+// server loads the profile of the authenticated user and attaches it to the
+// request context so tools can read it without knowing where it is stored.
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SourceUser marks settings the user chose explicitly.
+	SourceUser = "user"
+	// SourceBrowser marks settings detected from the browser; they never
+	// override an explicit choice.
+	SourceBrowser = "browser"
+)
+
+// Profile holds the settings of one user.
+type Profile struct {
+	TimeZone       string `json:"time_zone,omitempty"`
+	TimeZoneSource string `json:"time_zone_source,omitempty"`
+	// ProposeChanges makes mutating tools propose changes for the user to
+	// review instead of applying them right away.
+	ProposeChanges bool         `json:"propose_changes"`
+	Availability   Availability `json:"availability"`
+}
+
+// Location returns the profile's time zone, falling back to the server's.
+func (p Profile) Location() *time.Location {
+	if p.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// Store persists profiles as a JSON file keyed by user id.
+type Store struct {
+	path            string
+	defaultTimeZone string
+
+	mu       sync.Mutex
+	profiles map[string]Profile
+}
+
+// NewStore loads the profiles saved at path. defaultTimeZone is used for users
+// that have no time zone yet; it may be empty.
+func NewStore(path, defaultTimeZone string) (*Store, error) {
+	if defaultTimeZone != "" {
+		if _, err := time.LoadLocation(defaultTimeZone); err != nil {
+			return nil, fmt.Errorf("invalid default time zone %q: %w", defaultTimeZone, err)
+		}
+	}
+	s := &Store{
+		path:            path,
+		defaultTimeZone: defaultTimeZone,
+		profiles:        make(map[string]Profile),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.profiles); err != nil {
+		return nil, fmt.Errorf("couldn't parse profiles in %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the profile of userID with defaults applied.
+func (s *Store) Get(userID string) Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.profiles[userID]
+	if p.TimeZone == "" {
+		p.TimeZone = s.defaultTimeZone
+	}
+	return p
+}
+
+// Update applies fn to the stored profile of userID and saves the result.
+func (s *Store) Update(userID string, fn func(p *Profile) error) (Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.profiles[userID]
+	if err := fn(&p); err != nil {
+		return Profile{}, err
+	}
+	s.profiles[userID] = p
+	if err := s.save(); err != nil {
+		return Profile{}, err
+	}
+	if p.TimeZone == "" {
+		p.TimeZone = s.defaultTimeZone
+	}
+	return p, nil
+}
+
+// SetTimeZone stores tz for userID. Zones detected by the browser are only
+// applied while the user has not picked one explicitly.
+func (s *Store) SetTimeZone(userID, tz, source string) (Profile, error) {
+	tz = strings.TrimSpace(tz)
+	if _, err := time.LoadLocation(tz); err != nil || tz == "" {
+		return Profile{}, fmt.Errorf("invalid time zone %q", tz)
+	}
+	return s.Update(userID, func(p *Profile) error {
+		if source == SourceBrowser && p.TimeZoneSource == SourceUser {
+			return nil
+		}
+		p.TimeZone = tz
+		p.TimeZoneSource = source
+		return nil
+	})
+}
+
+// SetAvailability validates and stores the availability of userID. Weekday
+// names are matched case-insensitively.
+func (s *Store) SetAvailability(userID string, a Availability) (Profile, error) {
+	if len(a.WorkingHours) > 0 {
+		hours := make(map[string]TimeRange, len(a.WorkingHours))
+		for day, r := range a.WorkingHours {
+			hours[strings.ToLower(strings.TrimSpace(day))] = r
+		}
+		a.WorkingHours = hours
+	}
+	for i, b := range a.NoMeetings {
+		for j, day := range b.Days {
+			a.NoMeetings[i].Days[j] = strings.ToLower(strings.TrimSpace(day))
+		}
+	}
+	if err := a.Validate(); err != nil {
+		return Profile{}, err
+	}
+	return s.Update(userID, func(p *Profile) error {
+		p.Availability = a
+		return nil
+	})
+}
+
+// save writes the profiles atomically. The caller holds s.mu.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("couldn't create profile directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("couldn't save profiles: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p Profile) context.Context {
+	return context.WithValue(ctx, "UserProfile", p)
+}
+
+// FromContext returns the profile attached by NewContext, or an empty profile.
+func FromContext(ctx context.Context) Profile {
+	if ctx == nil {
+		return Profile{}
+	}
+	p, _ := ctx.Value("UserProfile").(Profile)
+	return p
+}
+
+// Location returns the time zone of the user making the request.
+func Location(ctx context.Context) *time.Location {
+	return FromContext(ctx).Location()
+}
@@ -0,0 +1,276 @@
+// Package recurring keeps the definitions of recurring tasks. Google Tasks has
+// no recurrence in its API, so GroundHog stores the rules itself and creates
+// the next instance of a task when the previous one is done or when its date
+// arrives. The package only stores rules and computes dates; creating the
+// tasks is up to the tasks tools.
+package recurring
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Frequencies a rule can repeat at.
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+)
+
+// Rule describes one recurring task. Dates are YYYY-MM-DD in the user's
+// time zone, matching the date-only due dates of Google Tasks.
+type Rule struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Notes      string `json:"notes,omitempty"`
+	TaskListID string `json:"task_list_id,omitempty"`
+	Frequency  string `json:"frequency"`
+	// Interval repeats the rule every n periods; 0 means 1.
+	Interval int `json:"interval,omitempty"`
+	// Weekdays restricts weekly rules to these days ("monday", ...). Empty
+	// means the weekday of Start.
+	Weekdays []string `json:"weekdays,omitempty"`
+	// Start is the first occurrence; later ones are counted from it.
+	Start string `json:"start"`
+
+	// TaskID and Due identify the latest instance created for the rule.
+	TaskID string `json:"task_id,omitempty"`
+	Due    string `json:"due,omitempty"`
+}
+
+// Validate normalises the rule and reports the first invalid field.
+func (r *Rule) Validate() error {
+	r.Title = strings.TrimSpace(r.Title)
+	if r.Title == "" {
+		return fmt.Errorf("title is required for a recurring task")
+	}
+	r.Frequency = strings.ToLower(strings.TrimSpace(r.Frequency))
+	switch r.Frequency {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return fmt.Errorf("frequency must be daily, weekly, monthly or yearly")
+	}
+	if r.Interval < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if _, err := time.Parse(time.DateOnly, r.Start); err != nil {
+		return fmt.Errorf("invalid start date %q; use YYYY-MM-DD", r.Start)
+	}
+	for i, day := range r.Weekdays {
+		wd, ok := parseWeekday(day)
+		if !ok {
+			return fmt.Errorf("invalid weekday %q", day)
+		}
+		r.Weekdays[i] = strings.ToLower(wd.String())
+	}
+	if len(r.Weekdays) > 0 && r.Frequency != Weekly {
+		return fmt.Errorf("weekdays only apply to weekly rules")
+	}
+	return nil
+}
+
+// First returns the first occurrence on or after Start.
+func (r Rule) First() time.Time {
+	start, _ := time.Parse(time.DateOnly, r.Start)
+	if r.occursOn(start) {
+		return start
+	}
+	return r.Next(start)
+}
+
+// Next returns the first occurrence strictly after day.
+func (r Rule) Next(day time.Time) time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	interval := max(r.Interval, 1)
+	// The longest gap between occurrences is a leap day every four years.
+	for i := 1; i <= 4*366*interval; i++ {
+		if d := day.AddDate(0, 0, i); r.occursOn(d) {
+			return d
+		}
+	}
+	return time.Time{}
+}
+
+// Describe renders the rule for people, e.g. "every 2 weeks on monday,
+// thursday".
+func (r Rule) Describe() string {
+	unit := map[string]string{Daily: "day", Weekly: "week", Monthly: "month", Yearly: "year"}[r.Frequency]
+	desc := "every " + unit
+	if r.Interval > 1 {
+		desc = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	if len(r.Weekdays) > 0 {
+		desc += " on " + strings.Join(r.Weekdays, ", ")
+	}
+	return desc
+}
+
+func (r Rule) occursOn(day time.Time) bool {
+	start, err := time.Parse(time.DateOnly, r.Start)
+	if err != nil || day.Before(start) {
+		return false
+	}
+	interval := max(r.Interval, 1)
+
+	switch r.Frequency {
+	case Daily:
+		return int(day.Sub(start).Hours()/24)%interval == 0
+	case Weekly:
+		if !r.onWeekday(day.Weekday(), start.Weekday()) {
+			return false
+		}
+		weeks := int(weekStart(day).Sub(weekStart(start)).Hours() / (24 * 7))
+		return weeks%interval == 0
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()-start.Month())
+		return months%interval == 0 && day.Day() == min(start.Day(), daysIn(day.Year(), day.Month()))
+	case Yearly:
+		years := day.Year() - start.Year()
+		return years%interval == 0 && day.Month() == start.Month() &&
+			day.Day() == min(start.Day(), daysIn(day.Year(), day.Month()))
+	}
+	return false
+}
+
+func (r Rule) onWeekday(wd, startWd time.Weekday) bool {
+	if len(r.Weekdays) == 0 {
+		return wd == startWd
+	}
+	for _, day := range r.Weekdays {
+		if d, ok := parseWeekday(day); ok && d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// weekStart returns the Monday of day's week.
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func parseWeekday(value string) (time.Weekday, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) < 3 {
+		return 0, false
+	}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.HasPrefix(strings.ToLower(wd.String()), value) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// Store persists the rules of all users as a JSON file keyed by user id.
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	rules map[string][]Rule
+}
+
+// Open loads the rules saved at path, creating the file on first write.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:  path,
+		rules: make(map[string][]Rule),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read recurring tasks: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.rules); err != nil {
+		return nil, fmt.Errorf("couldn't parse recurring tasks in %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// List returns the rules of userID.
+func (s *Store) List(userID string) []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Rule(nil), s.rules[userID]...)
+}
+
+// Save stores r for userID, replacing the rule with the same id. Rules
+// without an id get a new one.
+func (s *Store) Save(userID string, r Rule) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.ID == "" {
+		r.ID = newID()
+	}
+	rules := s.rules[userID]
+	replaced := false
+	for i := range rules {
+		if rules[i].ID == r.ID {
+			rules[i] = r
+			replaced = true
+		}
+	}
+	if !replaced {
+		rules = append(rules, r)
+	}
+	s.rules[userID] = rules
+	return r, s.save()
+}
+
+// Delete removes the rule id of userID and returns it.
+func (s *Store) Delete(userID, id string) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := s.rules[userID]
+	for i, r := range rules {
+		if r.ID == id {
+			s.rules[userID] = append(rules[:i:i], rules[i+1:]...)
+			return r, s.save()
+		}
+	}
+	return Rule{}, fmt.Errorf("no recurring task with id %q", id)
+}
+
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("couldn't create recurring tasks directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("couldn't save recurring tasks: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
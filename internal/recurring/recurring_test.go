@@ -0,0 +1,149 @@
+package recurring
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRuleOccurrences(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want []string
+	}{
+		{
+			name: "every other day",
+			rule: Rule{Frequency: Daily, Interval: 2, Start: "2025-12-10"},
+			want: []string{"2025-12-10", "2025-12-12", "2025-12-14"},
+		},
+		{
+			name: "weekly on the start weekday",
+			rule: Rule{Frequency: Weekly, Start: "2025-12-10"},
+			want: []string{"2025-12-10", "2025-12-17", "2025-12-24"},
+		},
+		{
+			name: "every two weeks on monday and thursday",
+			rule: Rule{Frequency: Weekly, Interval: 2, Weekdays: []string{"mon", "thu"}, Start: "2025-12-10"},
+			want: []string{"2025-12-11", "2025-12-22", "2025-12-25", "2026-01-05"},
+		},
+		{
+			name: "monthly on the 31st clamps to short months",
+			rule: Rule{Frequency: Monthly, Start: "2025-01-31"},
+			want: []string{"2025-01-31", "2025-02-28", "2025-03-31", "2025-04-30"},
+		},
+		{
+			name: "quarterly",
+			rule: Rule{Frequency: Monthly, Interval: 3, Start: "2025-11-15"},
+			want: []string{"2025-11-15", "2026-02-15", "2026-05-15"},
+		},
+		{
+			name: "yearly on a leap day",
+			rule: Rule{Frequency: Yearly, Start: "2024-02-29"},
+			want: []string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			day := tt.rule.First()
+			for range tt.want {
+				got = append(got, day.Format(time.DateOnly))
+				day = tt.rule.Next(day)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		want    Rule
+		wantErr bool
+	}{
+		{
+			name: "normalises",
+			rule: Rule{Title: " Water plants ", Frequency: "Weekly", Weekdays: []string{"Mon", "friday"}, Start: "2025-12-10"},
+			want: Rule{Title: "Water plants", Frequency: Weekly, Interval: 1, Weekdays: []string{"monday", "friday"}, Start: "2025-12-10"},
+		},
+		{name: "missing title", rule: Rule{Frequency: Daily, Start: "2025-12-10"}, wantErr: true},
+		{name: "unknown frequency", rule: Rule{Title: "x", Frequency: "hourly", Start: "2025-12-10"}, wantErr: true},
+		{name: "negative interval", rule: Rule{Title: "x", Frequency: Daily, Interval: -1, Start: "2025-12-10"}, wantErr: true},
+		{name: "bad start", rule: Rule{Title: "x", Frequency: Daily, Start: "10.12.2025"}, wantErr: true},
+		{name: "bad weekday", rule: Rule{Title: "x", Frequency: Weekly, Weekdays: []string{"mo"}, Start: "2025-12-10"}, wantErr: true},
+		{name: "weekdays on a monthly rule", rule: Rule{Title: "x", Frequency: Monthly, Weekdays: []string{"monday"}, Start: "2025-12-10"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.rule, tt.want) {
+				t.Errorf("Validate() left %+v, want %+v", tt.rule, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleDescribe(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{Frequency: Daily, Interval: 1}, "every day"},
+		{Rule{Frequency: Monthly, Interval: 3}, "every 3 months"},
+		{Rule{Frequency: Weekly, Interval: 2, Weekdays: []string{"monday", "thursday"}}, "every 2 weeks on monday, thursday"},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Describe(); got != tt.want {
+			t.Errorf("Describe(%+v) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "recurring.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	r, err := s.Save("ann", Rule{Title: "Water plants", Frequency: Weekly, Start: "2025-12-10"})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if r.ID == "" {
+		t.Fatal("Save didn't assign an id")
+	}
+	r.Due = "2025-12-17"
+	if _, err := s.Save("ann", r); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got := reopened.List("ann"); len(got) != 1 || !reflect.DeepEqual(got[0], r) {
+		t.Errorf("List(ann) = %+v, want [%+v]", got, r)
+	}
+	if got := reopened.List("bob"); len(got) != 0 {
+		t.Errorf("List(bob) = %+v, want none", got)
+	}
+
+	if _, err := reopened.Delete("bob", r.ID); err == nil {
+		t.Error("Delete of another user's rule succeeded")
+	}
+	if _, err := reopened.Delete("ann", r.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got := reopened.List("ann"); len(got) != 0 {
+		t.Errorf("List(ann) after Delete = %+v", got)
+	}
+}
@@ -0,0 +1,216 @@
+// Package scheduler places tasks into the free time of a day. It is
+// deterministic: the same request always yields the same plan, so the agent
+// doesn't have to invent times.
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Mode selects how tightly blocks are packed.
+type Mode string
+
+const (
+	// Strict schedules each task for exactly its estimate, back to back.
+	Strict Mode = "strict"
+	// Flexible adds slack to every estimate, rounds start times and leaves a
+	// short break after each block.
+	Flexible Mode = "flexible"
+)
+
+const (
+	// DefaultEstimate is used for tasks without an estimate.
+	DefaultEstimate = 30 * time.Minute
+
+	flexibleSlack       = 0.25
+	flexibleGranularity = 5 * time.Minute
+	flexibleBreak       = 5 * time.Minute
+)
+
+// Task is a unit of work to place on the calendar.
+type Task struct {
+	ID       string
+	Title    string
+	Estimate time.Duration
+	// Priority orders tasks: 1 is the most important; 0 means unset and
+	// sorts after every explicit priority.
+	Priority int
+	// Due is optional; earlier due dates are placed first within a priority.
+	Due time.Time
+}
+
+// Interval is a half-open time range [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+	Title string
+}
+
+// Request describes one planning run.
+type Request struct {
+	Mode Mode
+	// Working are the windows tasks may be placed in.
+	Working []Interval
+	// Busy are fixed events that must not be overlapped.
+	Busy  []Interval
+	Tasks []Task
+}
+
+// Block is a task placed at a time.
+type Block struct {
+	TaskID string
+	Title  string
+	Start  time.Time
+	End    time.Time
+}
+
+// Unscheduled is a task that didn't fit.
+type Unscheduled struct {
+	Task   Task
+	Reason string
+}
+
+// Plan is the result of Schedule.
+type Plan struct {
+	Blocks      []Block
+	Unscheduled []Unscheduled
+}
+
+// Schedule places the tasks of req greedily by priority into the earliest
+// free slot that fits them. Tasks are never split.
+func Schedule(req Request) Plan {
+	free := freeIntervals(req.Working, req.Busy)
+
+	tasks := make([]Task, len(req.Tasks))
+	copy(tasks, req.Tasks)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if rank(a.Priority) != rank(b.Priority) {
+			return rank(a.Priority) < rank(b.Priority)
+		}
+		if a.Due.IsZero() != b.Due.IsZero() {
+			return !a.Due.IsZero()
+		}
+		if !a.Due.Equal(b.Due) {
+			return a.Due.Before(b.Due)
+		}
+		return false
+	})
+
+	var plan Plan
+	for _, t := range tasks {
+		length := blockLength(req.Mode, t.Estimate)
+		placed := false
+		for i, slot := range free {
+			start := slot.Start
+			if req.Mode == Flexible {
+				start = roundUp(start, flexibleGranularity)
+			}
+			end := start.Add(length)
+			if end.After(slot.End) {
+				continue
+			}
+			plan.Blocks = append(plan.Blocks, Block{TaskID: t.ID, Title: t.Title, Start: start, End: end})
+
+			next := end
+			if req.Mode == Flexible {
+				next = end.Add(flexibleBreak)
+			}
+			free = splitSlot(free, i, start, next)
+			placed = true
+			break
+		}
+		if !placed {
+			plan.Unscheduled = append(plan.Unscheduled, Unscheduled{
+				Task:   t,
+				Reason: fmt.Sprintf("no free slot of %d min", int(length.Minutes())),
+			})
+		}
+	}
+
+	sort.SliceStable(plan.Blocks, func(i, j int) bool {
+		return plan.Blocks[i].Start.Before(plan.Blocks[j].Start)
+	})
+	return plan
+}
+
+// blockLength is the time reserved for a task with estimate in mode.
+func blockLength(mode Mode, estimate time.Duration) time.Duration {
+	if estimate <= 0 {
+		estimate = DefaultEstimate
+	}
+	if mode != Flexible {
+		return estimate
+	}
+	withSlack := estimate + time.Duration(float64(estimate)*flexibleSlack)
+	return roundUpDuration(withSlack, flexibleGranularity)
+}
+
+func rank(priority int) int {
+	if priority <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return priority
+}
+
+// freeIntervals subtracts busy from working and returns the remainder sorted
+// by start.
+func freeIntervals(working, busy []Interval) []Interval {
+	busy = append([]Interval(nil), busy...)
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+
+	var free []Interval
+	for _, w := range working {
+		cursor := w.Start
+		for _, b := range busy {
+			if !b.End.After(cursor) || !b.Start.Before(w.End) {
+				continue
+			}
+			if b.Start.After(cursor) {
+				free = append(free, Interval{Start: cursor, End: b.Start})
+			}
+			if b.End.After(cursor) {
+				cursor = b.End
+			}
+		}
+		if w.End.After(cursor) {
+			free = append(free, Interval{Start: cursor, End: w.End})
+		}
+	}
+	sort.Slice(free, func(i, j int) bool { return free[i].Start.Before(free[j].Start) })
+	return free
+}
+
+// splitSlot removes [start, end) from free[i], keeping what remains on
+// either side.
+func splitSlot(free []Interval, i int, start, end time.Time) []Interval {
+	slot := free[i]
+	var parts []Interval
+	if start.After(slot.Start) {
+		parts = append(parts, Interval{Start: slot.Start, End: start})
+	}
+	if slot.End.After(end) {
+		parts = append(parts, Interval{Start: end, End: slot.End})
+	}
+	res := make([]Interval, 0, len(free)+1)
+	res = append(res, free[:i]...)
+	res = append(res, parts...)
+	return append(res, free[i+1:]...)
+}
+
+func roundUp(t time.Time, step time.Duration) time.Time {
+	r := t.Truncate(step)
+	if r.Before(t) {
+		r = r.Add(step)
+	}
+	return r
+}
+
+func roundUpDuration(d, step time.Duration) time.Duration {
+	if rem := d % step; rem != 0 {
+		d += step - rem
+	}
+	return d
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, time.December, 10, hour, minute, 0, 0, time.UTC)
+	}
+	day := []Interval{{Start: at(9, 0), End: at(12, 0)}}
+
+	tests := []struct {
+		name        string
+		req         Request
+		want        []Block
+		unscheduled []string
+	}{
+		{
+			name: "strict packs back to back around busy time",
+			req: Request{
+				Mode:    Strict,
+				Working: day,
+				Busy:    []Interval{{Start: at(9, 30), End: at(10, 0), Title: "Standup"}},
+				Tasks: []Task{
+					{ID: "a", Title: "A", Estimate: 30 * time.Minute},
+					{ID: "b", Title: "B", Estimate: 45 * time.Minute},
+				},
+			},
+			want: []Block{
+				{TaskID: "a", Title: "A", Start: at(9, 0), End: at(9, 30)},
+				{TaskID: "b", Title: "B", Start: at(10, 0), End: at(10, 45)},
+			},
+		},
+		{
+			name: "priority and due date order placement",
+			req: Request{
+				Mode:    Strict,
+				Working: day,
+				Tasks: []Task{
+					{ID: "unset", Title: "Unset", Estimate: time.Hour},
+					{ID: "later", Title: "Later", Estimate: time.Hour, Priority: 2, Due: at(18, 0)},
+					{ID: "sooner", Title: "Sooner", Estimate: time.Hour, Priority: 2, Due: at(15, 0)},
+					{ID: "urgent", Title: "Urgent", Estimate: time.Hour, Priority: 1},
+				},
+			},
+			want: []Block{
+				{TaskID: "urgent", Title: "Urgent", Start: at(9, 0), End: at(10, 0)},
+				{TaskID: "sooner", Title: "Sooner", Start: at(10, 0), End: at(11, 0)},
+				{TaskID: "later", Title: "Later", Start: at(11, 0), End: at(12, 0)},
+			},
+			unscheduled: []string{"unset"},
+		},
+		{
+			name: "flexible adds slack, rounds starts and leaves a break",
+			req: Request{
+				Mode:    Flexible,
+				Working: []Interval{{Start: at(9, 2), End: at(12, 0)}},
+				Tasks: []Task{
+					{ID: "a", Title: "A", Estimate: 30 * time.Minute},
+					{ID: "b", Title: "B"},
+				},
+			},
+			want: []Block{
+				{TaskID: "a", Title: "A", Start: at(9, 5), End: at(9, 45)},
+				{TaskID: "b", Title: "B", Start: at(9, 50), End: at(10, 30)},
+			},
+		},
+		{
+			name: "a task is never split across slots",
+			req: Request{
+				Mode:    Strict,
+				Working: []Interval{{Start: at(9, 0), End: at(10, 0)}, {Start: at(14, 0), End: at(15, 0)}},
+				Busy:    []Interval{{Start: at(9, 30), End: at(14, 30)}},
+				Tasks:   []Task{{ID: "long", Title: "Long", Estimate: 45 * time.Minute}},
+			},
+			unscheduled: []string{"long"},
+		},
+		{
+			name: "later free slot is used when the first is too short",
+			req: Request{
+				Mode:    Strict,
+				Working: day,
+				Busy:    []Interval{{Start: at(9, 20), End: at(10, 0)}},
+				Tasks:   []Task{{ID: "a", Title: "A", Estimate: 30 * time.Minute}},
+			},
+			want: []Block{{TaskID: "a", Title: "A", Start: at(10, 0), End: at(10, 30)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := Schedule(tt.req)
+			if len(plan.Blocks) != len(tt.want) {
+				t.Fatalf("Blocks = %+v, want %+v", plan.Blocks, tt.want)
+			}
+			for i, b := range plan.Blocks {
+				w := tt.want[i]
+				if b.TaskID != w.TaskID || b.Title != w.Title || !b.Start.Equal(w.Start) || !b.End.Equal(w.End) {
+					t.Errorf("Blocks[%d] = %+v, want %+v", i, b, w)
+				}
+			}
+			if len(plan.Unscheduled) != len(tt.unscheduled) {
+				t.Fatalf("Unscheduled = %+v, want %v", plan.Unscheduled, tt.unscheduled)
+			}
+			for i, u := range plan.Unscheduled {
+				if u.Task.ID != tt.unscheduled[i] || u.Reason == "" {
+					t.Errorf("Unscheduled[%d] = %+v, want task %q with a reason", i, u, tt.unscheduled[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBlockLength(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		estimate time.Duration
+		want     time.Duration
+	}{
+		{Strict, 0, DefaultEstimate},
+		{Strict, 20 * time.Minute, 20 * time.Minute},
+		{Flexible, 20 * time.Minute, 25 * time.Minute},
+		{Flexible, 30 * time.Minute, 40 * time.Minute},
+		{Flexible, time.Hour, 75 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := blockLength(tt.mode, tt.estimate); got != tt.want {
+			t.Errorf("blockLength(%s, %s) = %s, want %s", tt.mode, tt.estimate, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"groundhog/internal/profile"
+	"groundhog/internal/tools/calendar"
+	"groundhog/internal/tools/tasks"
+)
+
+// EventDTO is the JSON form of a calendar event. Timed events are reported in
+// the user's time zone; all-day events carry dates only.
+type EventDTO struct {
+	ID          string `json:"id"`
+	Summary     string `json:"summary"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Calendar    string `json:"calendar,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	AllDay      bool   `json:"all_day"`
+	TimeZone    string `json:"time_zone,omitempty"`
+	Link        string `json:"link,omitempty"`
+}
+
+// TaskDTO is the JSON form of a task. Due is a date because Google Tasks
+// drops the time of day.
+type TaskDTO struct {
+	ID        string `json:"id"`
+	ListID    string `json:"list_id"`
+	Title     string `json:"title"`
+	Notes     string `json:"notes,omitempty"`
+	Status    string `json:"status"`
+	Due       string `json:"due,omitempty"`
+	Completed string `json:"completed,omitempty"`
+	Parent    string `json:"parent,omitempty"`
+	Link      string `json:"link,omitempty"`
+
+	EstimateMinutes int    `json:"estimate_minutes,omitempty"`
+	Priority        int    `json:"priority,omitempty"`
+	Energy          string `json:"energy,omitempty"`
+	Goal            string `json:"goal,omitempty"`
+}
+
+// EventsHandler serves GET /api/events.
+//
+// Query parameters:
+//   - from, to: YYYY-MM-DD or RFC3339; default now and 7 days later
+//   - q: case-insensitive match on summary, description and location
+//   - status: confirmed, tentative or cancelled
+//   - all_day: true or false
+func EventsHandler(provider calendar.CalendarProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		loc := profile.Location(r.Context())
+		now := time.Now().In(loc)
+		from, err := parseRangeParam(query.Get("from"), now, loc)
+		if err != nil {
+			http.Error(w, "Invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := parseRangeParam(query.Get("to"), from.AddDate(0, 0, 7), loc)
+		if err != nil {
+			http.Error(w, "Invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !to.After(from) {
+			http.Error(w, "to must be after from", http.StatusBadRequest)
+			return
+		}
+		var allDay *bool
+		if v := query.Get("all_day"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				http.Error(w, "Invalid all_day", http.StatusBadRequest)
+				return
+			}
+			allDay = &b
+		}
+		text := strings.ToLower(strings.TrimSpace(query.Get("q")))
+		status := strings.TrimSpace(query.Get("status"))
+
+		events, err := provider.ListEvents(r.Context(), from, to)
+		if err != nil {
+			log.Println("Listing events failed:", err)
+			http.Error(w, "Couldn't list events", http.StatusBadGateway)
+			return
+		}
+
+		res := make([]EventDTO, 0, len(events))
+		for _, e := range events {
+			if allDay != nil && e.AllDay != *allDay {
+				continue
+			}
+			if status != "" && !strings.EqualFold(e.Status, status) {
+				continue
+			}
+			if text != "" && !containsFold(text, e.Summary, e.Description, e.Location) {
+				continue
+			}
+			res = append(res, newEventDTO(e, loc))
+		}
+		writeJSON(w, res)
+	}
+}
+
+// TasksHandler serves GET /api/tasks.
+//
+// Query parameters:
+//   - list: task list id; defaults to the primary list
+//   - status: needsAction (default), completed or all
+//   - due_from, due_to: YYYY-MM-DD or RFC3339
+//   - completed_from, completed_to: YYYY-MM-DD or RFC3339; implies completed
+//   - q: case-insensitive match on title and notes
+//   - limit: 1-500, default 100
+func TasksHandler(lister *tasks.ListTasks) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		q := tasks.TaskQuery{
+			TaskListID: query.Get("list"),
+			MaxResults: 100,
+		}
+
+		switch status := strings.TrimSpace(query.Get("status")); status {
+		case "", "needsAction":
+			q.Status = "needsAction"
+		case "completed":
+			q.Status = "completed"
+		case "all":
+			q.IncludeCompleted = true
+		default:
+			http.Error(w, "status must be needsAction, completed or all", http.StatusBadRequest)
+			return
+		}
+
+		// Due dates are stored as UTC midnight, so compare dates in UTC and
+		// make due_to include its whole day.
+		var err error
+		if q.DueMin, err = parseRangeParam(query.Get("due_from"), time.Time{}, time.UTC); err != nil {
+			http.Error(w, "Invalid due_from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.DueMax, err = parseRangeParam(query.Get("due_to"), time.Time{}, time.UTC); err != nil {
+			http.Error(w, "Invalid due_to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := time.Parse(time.DateOnly, query.Get("due_to")); err == nil {
+			q.DueMax = q.DueMax.AddDate(0, 0, 1)
+		}
+		// Completion times are instants, so dates are days in the user's zone.
+		loc := profile.Location(r.Context())
+		if q.CompletedMin, err = parseRangeParam(query.Get("completed_from"), time.Time{}, loc); err != nil {
+			http.Error(w, "Invalid completed_from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.CompletedMax, err = parseRangeParam(query.Get("completed_to"), time.Time{}, loc); err != nil {
+			http.Error(w, "Invalid completed_to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := time.Parse(time.DateOnly, query.Get("completed_to")); err == nil {
+			q.CompletedMax = q.CompletedMax.AddDate(0, 0, 1)
+		}
+		if v := query.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 500 {
+				http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+				return
+			}
+			q.MaxResults = n
+		}
+		q.Query = query.Get("q")
+
+		items, _, err := lister.Fetch(r.Context(), q)
+		if err != nil {
+			log.Println("Listing tasks failed:", err)
+			http.Error(w, "Couldn't list tasks", http.StatusBadGateway)
+			return
+		}
+
+		res := make([]TaskDTO, 0, len(items))
+		for _, t := range items {
+			res = append(res, newTaskDTO(t))
+		}
+		writeJSON(w, res)
+	}
+}
+
+func newEventDTO(e calendar.Event, loc *time.Location) EventDTO {
+	dto := EventDTO{
+		ID:          e.ID,
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+		Calendar:    e.Calendar,
+		Status:      e.Status,
+		AllDay:      e.AllDay,
+		TimeZone:    e.TimeZone,
+		Link:        e.HTMLLink,
+	}
+	if e.AllDay {
+		dto.Start = e.Start.Format(time.DateOnly)
+		dto.End = e.End.Format(time.DateOnly)
+	} else {
+		dto.Start = e.Start.In(loc).Format(time.RFC3339)
+		dto.End = e.End.In(loc).Format(time.RFC3339)
+	}
+	return dto
+}
+
+func newTaskDTO(t tasks.Task) TaskDTO {
+	dto := TaskDTO{
+		ID:     t.ID,
+		ListID: t.ListID,
+		Title:  t.Title,
+		Notes:  t.Notes,
+		Status: t.Status,
+		Parent: t.Parent,
+		Link:   t.WebLink,
+
+		EstimateMinutes: t.Meta.EstimateMinutes,
+		Priority:        t.Meta.Priority,
+		Energy:          t.Meta.Energy,
+		Goal:            t.Meta.Goal,
+	}
+	if !t.Due.IsZero() {
+		dto.Due = t.Due.UTC().Format(time.DateOnly)
+	}
+	if !t.Completed.IsZero() {
+		dto.Completed = t.Completed.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// containsFold reports whether any of fields contains the lower-cased needle.
+func containsFold(needle string, fields ...string) bool {
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Failed to encode response:", err)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"groundhog/internal/profile"
+	"groundhog/internal/tools/calendar"
+)
+
+const maxImportSize = 10 << 20
+
+// ExportICSHandler serves GET /api/calendar.ics?from=&to= where from and to
+// are YYYY-MM-DD or RFC3339. The range defaults to the last 30 and next 90 days.
+func ExportICSHandler(provider calendar.CalendarProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		now := time.Now()
+		loc := profile.Location(r.Context())
+		from, err := parseRangeParam(r.URL.Query().Get("from"), now.AddDate(0, 0, -30), loc)
+		if err != nil {
+			http.Error(w, "Invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := parseRangeParam(r.URL.Query().Get("to"), now.AddDate(0, 0, 90), loc)
+		if err != nil {
+			http.Error(w, "Invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !to.After(from) {
+			http.Error(w, "to must be after from", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="groundhog.ics"`)
+		if _, err := calendar.ExportICS(r.Context(), provider, from, to, w); err != nil {
+			log.Println("ICS export failed:", err)
+			http.Error(w, "Couldn't export calendar", http.StatusBadGateway)
+		}
+	}
+}
+
+// ImportICSHandler serves POST /api/calendar/import. The .ics file is taken
+// from the "file" field of a multipart form or from the raw request body.
+func ImportICSHandler(provider calendar.CalendarProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, "Provide the calendar in the file field", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		result, err := calendar.ImportICS(r.Context(), provider, body)
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			log.Println("ICS import failed:", err)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{
+				"error":  err.Error(),
+				"result": result,
+			})
+			return
+		}
+		if result.Failed > 0 {
+			// Some events were imported and some weren't; the per-event
+			// results tell which.
+			w.WriteHeader(http.StatusMultiStatus)
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Println("Failed to encode import result:", err)
+		}
+	}
+}
+
+func parseRangeParam(value string, fallback time.Time, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("use YYYY-MM-DD or RFC3339")
+}
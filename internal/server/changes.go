@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"log"
+
+	"groundhog/internal/changes"
+
+	"github.com/gorilla/websocket"
+)
+
+// ServerMessage is what the server sends over the websocket. Type is
+// "message" for agent replies, "changes" for the pending change set and
+// "change_results" after the user decided on changes.
+type ServerMessage struct {
+	Type    string           `json:"type"`
+	Message string           `json:"message,omitempty"`
+	Changes []changes.Change `json:"changes,omitempty"`
+}
+
+func writeServerMessage(ws *websocket.Conn, msg ServerMessage) error {
+	return ws.WriteJSON(msg)
+}
+
+func writeText(ws *websocket.Conn, text string) error {
+	return writeServerMessage(ws, ServerMessage{Type: "message", Message: text})
+}
+
+// handleChangeMessage applies or rejects proposed changes. It reports whether
+// msg was a change decision.
+func handleChangeMessage(ctx context.Context, ws *websocket.Conn, set *changes.Set, msg WebSocketMessage) bool {
+	var results []changes.Change
+	switch msg.Type {
+	case "apply":
+		for _, id := range msg.IDs {
+			c, err := set.Apply(ctx, id)
+			if err != nil {
+				log.Println(err)
+			}
+			if c.ID != "" {
+				results = append(results, c)
+			}
+		}
+	case "reject":
+		for _, id := range msg.IDs {
+			c, err := set.Reject(id)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			results = append(results, c)
+		}
+	case "apply_all":
+		results = set.ApplyAll(ctx)
+	case "reject_all":
+		results = set.RejectAll()
+	default:
+		return false
+	}
+
+	if err := writeServerMessage(ws, ServerMessage{Type: "change_results", Changes: results}); err != nil {
+		log.Println("Write error:", err)
+	}
+	return true
+}
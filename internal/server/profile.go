@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"groundhog/internal/profile"
+)
+
+// userID returns the id authMiddleware attached to the request.
+func userID(r *http.Request) string {
+	id, _ := r.Context().Value("UserID").(string)
+	return id
+}
+
+// withProfile attaches the authenticated user's profile to the request
+// context. It must run inside authMiddleware.
+func withProfile(profiles *profile.Store, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := profiles.Get(userID(r))
+		next(w, r.WithContext(profile.NewContext(r.Context(), p)))
+	}
+}
+
+// ProfileHandler serves GET and PUT /api/profile for the current user.
+func ProfileHandler(profiles *profile.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req struct {
+				TimeZone       string                `json:"time_zone"`
+				ProposeChanges *bool                 `json:"propose_changes"`
+				Availability   *profile.Availability `json:"availability"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+				return
+			}
+			if req.TimeZone != "" {
+				if _, err := profiles.SetTimeZone(userID(r), req.TimeZone, profile.SourceUser); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			if req.Availability != nil {
+				if _, err := profiles.SetAvailability(userID(r), *req.Availability); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			if req.ProposeChanges != nil {
+				if _, err := profiles.Update(userID(r), func(p *profile.Profile) error {
+					p.ProposeChanges = *req.ProposeChanges
+					return nil
+				}); err != nil {
+					log.Println("Failed to save profile:", err)
+					http.Error(w, "Couldn't save profile", http.StatusInternalServerError)
+					return
+				}
+			}
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(profiles.Get(userID(r))); err != nil {
+			log.Println("Failed to encode profile:", err)
+		}
+	}
+}
@@ -10,7 +10,11 @@ import (
 	"net/url"
 	"os"
 
+	"groundhog/internal/agent"
+	"groundhog/internal/changes"
+	"groundhog/internal/oplog"
 	"groundhog/internal/patterns"
+	"groundhog/internal/profile"
 	"groundhog/internal/tools/calendar"
 	"groundhog/internal/tools/tasks"
 
@@ -34,10 +38,18 @@ type TokenClaims struct {
 
 var hmacSecret = []byte(os.Getenv("JWT_SECRET"))
 
+// OwnerUserID identifies the single user of a master-password installation.
+const OwnerUserID = "owner"
+
 // WebSocketMessage defines the structure for incoming JSON messages from the frontend.
 type WebSocketMessage struct {
-	Message string `json:"message"`
-	Pattern string `json:"pattern"`
+	// Type is empty for chat messages; "hello" is sent once on connect and
+	// apply, reject, apply_all and reject_all decide on proposed changes.
+	Type     string   `json:"type,omitempty"`
+	Message  string   `json:"message"`
+	Pattern  string   `json:"pattern"`
+	TimeZone string   `json:"time_zone,omitempty"`
+	IDs      []string `json:"ids,omitempty"`
 }
 
 var upgrader = websocket.Upgrader{
@@ -86,15 +98,39 @@ func (o *oauthHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie := createTokenCookie(token, w)
+	// Data is kept per account, so a login without one would share another
+	// user's calendar cache, undo log and profile.
+	subject := oauthSubject(token)
+	if subject == "" {
+		http.Error(w, "Google didn't identify the account; grant the openid scope and log in again", http.StatusForbidden)
+		return
+	}
+	cookie := createTokenCookie(token, subject, w)
 	http.SetCookie(w, &cookie)
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func createToken(token *oauth2.Token) (string, error) {
+// oauthSubject returns the Google account id from the OpenID Connect id_token
+// returned with the access token. The token comes straight from Google's token
+// endpoint, so its payload is read without verifying the signature.
+func oauthSubject(token *oauth2.Token) string {
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok || idToken == "" {
+		return ""
+	}
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		log.Println("Couldn't read id_token:", err)
+		return ""
+	}
+	return claims.Subject
+}
+
+func createToken(token *oauth2.Token, subject string) (string, error) {
 	claims := TokenClaims{
 		OauthToken: token,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(2 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "localhost",
@@ -156,12 +192,20 @@ func authMiddleware(oauthConfig *oauth2.Config, next http.HandlerFunc) http.Hand
 		if tokenSource != nil {
 			r = r.WithContext(context.WithValue(r.Context(), "OauthTokenSource", tokenSource))
 		}
+		// Every session names its user: the master password logs in as
+		// OwnerUserID and OAuth logins as their Google account.
+		if claims.Subject == "" {
+			log.Println("Session without a user id")
+			http.Redirect(w, r, loginUrl, http.StatusSeeOther)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), "UserID", claims.Subject))
 		next(w, r)
 	}
 }
 
-func createTokenCookie(token *oauth2.Token, w http.ResponseWriter) http.Cookie {
-	t, err := createToken(token)
+func createTokenCookie(token *oauth2.Token, subject string, w http.ResponseWriter) http.Cookie {
+	t, err := createToken(token, subject)
 	if err != nil {
 		w.Write([]byte("Couldn't create jwt token"))
 		log.Println(err)
@@ -196,7 +240,7 @@ func groundhogLoginHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if subtle.ConstantTimeCompare([]byte(password[0]), []byte(master_password)) == 1 {
-			cookie := createTokenCookie(nil, w)
+			cookie := createTokenCookie(nil, OwnerUserID, w)
 			http.SetCookie(w, &cookie)
 			v := r.URL.Query().Get("next")
 
@@ -230,7 +274,10 @@ func CallendarHandler(c tools.Tool) http.HandlerFunc {
 	}
 }
 
-func New(agentExecutor *agents.Executor, oauthConfig *oauth2.Config) http.Handler {
+// New builds the server's routes. recurringTasks is synced when a user
+// connects; leave it nil when Google Tasks isn't configured or a background
+// run keeps the recurring tasks up to date.
+func New(agentExecutor *agents.Executor, oauthConfig *oauth2.Config, profiles *profile.Store, opLog *oplog.Log, recurringTasks *tasks.RecurringSync) http.Handler {
 	mux := http.NewServeMux()
 
 	// API to get patterns
@@ -245,19 +292,25 @@ func New(agentExecutor *agents.Executor, oauthConfig *oauth2.Config) http.Handle
 			tasksTool = tool
 		}
 	}
-	if calendarTool != nil {
-		t, ok := tasksTool.(*tasks.ListTasks)
-		if !ok {
-			fmt.Println("Couldn't create tasks tool")
-		} else {
-			mux.HandleFunc("/tasks", authMiddleware(oauthConfig, CallendarHandler(t)))
-		}
+	// Tasks don't need a calendar: a markdown-only install has no calendar
+	// tool at all.
+	if t, ok := tasksTool.(*tasks.ListTasks); ok {
+		mux.HandleFunc("/tasks", authMiddleware(oauthConfig, withProfile(profiles, CallendarHandler(t))))
+		mux.HandleFunc("/api/tasks", authMiddleware(oauthConfig, withProfile(profiles, TasksHandler(t))))
+		mux.HandleFunc("/api/tasks/import", authMiddleware(oauthConfig, withProfile(profiles, withOperationLog(opLog, ImportTasksHandler(t.Provider())))))
+	} else if tasksTool != nil {
+		fmt.Println("Couldn't create tasks tool")
+	}
 
+	if calendarTool != nil {
 		c, ok := calendarTool.(*calendar.Calendar)
 		if !ok {
 			fmt.Println("Couldn't create calendar tool")
 		} else {
-			mux.HandleFunc("/calendar", authMiddleware(oauthConfig, CallendarHandler(c)))
+			mux.HandleFunc("/calendar", authMiddleware(oauthConfig, withProfile(profiles, CallendarHandler(c))))
+			mux.HandleFunc("/api/events", authMiddleware(oauthConfig, withProfile(profiles, EventsHandler(c.Provider()))))
+			mux.HandleFunc("/api/calendar.ics", authMiddleware(oauthConfig, withProfile(profiles, ExportICSHandler(c.Provider()))))
+			mux.HandleFunc("/api/calendar/import", authMiddleware(oauthConfig, withProfile(profiles, withOperationLog(opLog, ImportICSHandler(c.Provider())))))
 		}
 	}
 
@@ -269,9 +322,12 @@ func New(agentExecutor *agents.Executor, oauthConfig *oauth2.Config) http.Handle
 	// API to get patterns
 	mux.HandleFunc("/patterns", handlePatterns)
 
+	mux.HandleFunc("/api/profile", authMiddleware(oauthConfig, ProfileHandler(profiles)))
+	mux.HandleFunc("/api/undo", authMiddleware(oauthConfig, withProfile(profiles, UndoHandler(opLog))))
+
 	// Websocket route
 	mux.HandleFunc("/ws", authMiddleware(oauthConfig, func(w http.ResponseWriter, r *http.Request) {
-		handleConnections(w, r, agentExecutor)
+		handleConnections(w, r.WithContext(oplog.NewContext(r.Context(), opLog)), agentExecutor, profiles, recurringTasks)
 	}))
 
 	if oauthConfig != nil {
@@ -285,13 +341,17 @@ func New(agentExecutor *agents.Executor, oauthConfig *oauth2.Config) http.Handle
 	return mux
 }
 
-func handleConnections(w http.ResponseWriter, r *http.Request, executor *agents.Executor) {
+func handleConnections(w http.ResponseWriter, r *http.Request, executor *agents.Executor, profiles *profile.Store, recurringTasks *tasks.RecurringSync) {
 	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	executor.Memory = memory.NewConversationBuffer()
+	// The prompt takes more inputs than the user's message, so tell the
+	// memory which one to record.
+	executor.Memory = memory.NewConversationBuffer(memory.WithInputKey("input"))
+	// Changes proposed by tools wait here until the user decides on them.
+	changeSet := changes.NewSet()
 
 	defer ws.Close()
 
@@ -312,9 +372,23 @@ func handleConnections(w http.ResponseWriter, r *http.Request, executor *agents.
 			continue
 		}
 
+		if handleChangeMessage(oplog.WithTurn(profile.NewContext(r.Context(), profiles.Get(userID(r)))), ws, changeSet, msg) {
+			continue
+		}
+
+		if msg.Type == "hello" {
+			if msg.TimeZone != "" {
+				if _, err := profiles.SetTimeZone(userID(r), msg.TimeZone, profile.SourceBrowser); err != nil {
+					log.Println("Couldn't store detected time zone:", err)
+				}
+			}
+			syncRecurring(profile.NewContext(r.Context(), profiles.Get(userID(r))), recurringTasks)
+			continue
+		}
+
 		memory, err := executor.Memory.LoadMemoryVariables(context.Background(), map[string]any{})
 		if err != nil{
-			if writeErr := ws.WriteMessage(websocket.TextMessage, []byte("Sorry, I encountered an error.")); writeErr != nil {
+			if writeErr := writeText(ws, "Sorry, I encountered an error."); writeErr != nil {
 				log.Println("Write error:", writeErr)
 			}
 			continue
@@ -323,7 +397,7 @@ func handleConnections(w http.ResponseWriter, r *http.Request, executor *agents.
 		memoryKey := executor.Memory.GetMemoryKey(context.Background())
 		firstMessage := memory[memoryKey] == ""
 		if !firstMessage && msg.Message == ""{
-			if writeErr := ws.WriteMessage(websocket.TextMessage, []byte("Please provide some message text")); writeErr != nil {
+			if writeErr := writeText(ws, "Please provide some message text"); writeErr != nil {
 				log.Println("Write error:", writeErr)
 			}
 			continue
@@ -345,15 +419,21 @@ func handleConnections(w http.ResponseWriter, r *http.Request, executor *agents.
 		fmt.Println(userInput)
 
 
-		output, err := chains.Call(r.Context(), executor, map[string]any{
-			"input": userInput,
-		})
+		userProfile := profiles.Get(userID(r))
+		inputs := agent.PromptInputs(userProfile)
+		inputs["input"] = userInput
+
+		ctx := changes.NewContext(oplog.WithTurn(profile.NewContext(r.Context(), userProfile)), changeSet)
+		if userProfile.ProposeChanges {
+			ctx = changes.WithReview(ctx)
+		}
+		output, err := chains.Call(ctx, executor, inputs)
 
 		if err != nil {
 			log.Printf("Agent Error: %v\n", err)
 			log.Printf("Full response on error: %+v\n", output)
 
-			if writeErr := ws.WriteMessage(websocket.TextMessage, []byte("Sorry, I encountered an error.")); writeErr != nil {
+			if writeErr := writeText(ws, "Sorry, I encountered an error."); writeErr != nil {
 				log.Println("Write error:", writeErr)
 			}
 			continue
@@ -363,7 +443,25 @@ func handleConnections(w http.ResponseWriter, r *http.Request, executor *agents.
 		if !ok {
 			log.Println("Couldn't get proper output from llm")
 		}
-		ws.WriteMessage(websocket.TextMessage, []byte(response))
+		if err := writeText(ws, response); err != nil {
+			log.Println("Write error:", err)
+		}
+		if pending := changeSet.Pending(); len(pending) > 0 {
+			if err := writeServerMessage(ws, ServerMessage{Type: "changes", Changes: pending}); err != nil {
+				log.Println("Write error:", err)
+			}
+		}
+	}
+}
+
+// syncRecurring creates the recurring tasks that became due. It runs once
+// per connection for OAuth users, whose tasks no background run can reach.
+func syncRecurring(ctx context.Context, recurringTasks *tasks.RecurringSync) {
+	if recurringTasks == nil {
+		return
+	}
+	if _, err := recurringTasks.Sync(ctx); err != nil {
+		log.Println("Couldn't sync recurring tasks:", err)
 	}
 }
 
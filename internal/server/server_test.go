@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"groundhog/internal/oplog"
+	"groundhog/internal/profile"
+	"groundhog/internal/tools/tasks"
+
+	"github.com/tmc/langchaingo/agents"
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// toolsAgent only offers its tools; the routes never ask it to plan.
+type toolsAgent []tools.Tool
+
+func (a toolsAgent) Plan(context.Context, []schema.AgentStep, map[string]string, ...chains.ChainCallOption) ([]schema.AgentAction, *schema.AgentFinish, error) {
+	return nil, &schema.AgentFinish{}, nil
+}
+
+func (a toolsAgent) GetInputKeys() []string  { return []string{"input"} }
+func (a toolsAgent) GetOutputKeys() []string { return []string{"output"} }
+func (a toolsAgent) GetTools() []tools.Tool  { return a }
+
+func TestTaskRoutesWithoutCalendar(t *testing.T) {
+	hmacSecret = []byte("test secret")
+	dir := t.TempDir()
+	profiles, err := profile.NewStore(filepath.Join(dir, "profiles.json"), "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opLog, err := oplog.Open(filepath.Join(dir, "oplog.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := tasks.NewMarkdownTaskProvider(filepath.Join(dir, "tasks.md"))
+	executor := agents.NewExecutor(toolsAgent{tasks.NewListTasks(provider)})
+	handler := New(executor, nil, profiles, opLog, nil)
+
+	token, err := createToken(nil, OwnerUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   string
+	}{
+		{"import", http.MethodPost, "/api/tasks/import?list=Inbox", "title,due\nBuy milk,2025-12-11\n", `"created":1`},
+		{"list", http.MethodGet, "/api/tasks?list=Inbox", "", "Buy milk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.AddCookie(&http.Cookie{Name: "Auth", Value: token})
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tt.want) {
+				t.Fatalf("%s %s = %d %s, want 200 with %s", tt.method, tt.target, rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"groundhog/internal/tools/tasks"
+)
+
+// ImportTasksHandler serves POST /api/tasks/import?format=&list=&dry_run=&include_completed=.
+// The export is taken from the "file" field of a multipart form or from the
+// raw request body; format is detected when omitted. With dry_run=true the
+// response previews the tasks without creating them.
+func ImportTasksHandler(provider tasks.TaskProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		opts := tasks.ImportOptions{
+			Format:           strings.ToLower(strings.TrimSpace(q.Get("format"))),
+			List:             strings.TrimSpace(q.Get("list")),
+			DryRun:           q.Get("dry_run") == "true",
+			IncludeCompleted: q.Get("include_completed") == "true",
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, "Provide the export in the file field", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		result, err := tasks.ImportTasks(r.Context(), provider, body, opts)
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			log.Println("Task import failed:", err)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{
+				"error":  err.Error(),
+				"result": result,
+			})
+			return
+		}
+		if result.Failed > 0 {
+			// Created says how many tasks made it; the failed ones carry
+			// their error.
+			w.WriteHeader(http.StatusMultiStatus)
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Println("Failed to encode import result:", err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"groundhog/internal/oplog"
+)
+
+// withOperationLog makes tool code called by next record its changes in l.
+func withOperationLog(l *oplog.Log, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := oplog.WithTurn(oplog.NewContext(r.Context(), l))
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// UndoHandler serves POST /api/undo with an optional JSON body
+// {"scope": "change" | "turn"}. It reverts the current user's latest change,
+// or all changes of the latest agent turn.
+func UndoHandler(l *oplog.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Scope string `json:"scope"`
+		}
+		// An empty body, chunked or not, means "undo the last change".
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.Scope != "" && req.Scope != "change" && req.Scope != "turn" {
+			http.Error(w, `scope must be "change" or "turn"`, http.StatusBadRequest)
+			return
+		}
+
+		ops := l.Last(r.Context(), req.Scope == "turn")
+		reverted, err := l.Undo(r.Context(), ops)
+		resp := map[string]any{
+			"reverted": reverted,
+		}
+		if err != nil {
+			resp["error"] = err.Error()
+			w.WriteHeader(http.StatusConflict)
+		}
+		writeJSON(w, resp)
+	}
+}
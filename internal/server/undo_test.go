@@ -0,0 +1,40 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"groundhog/internal/oplog"
+)
+
+func TestUndoHandlerBody(t *testing.T) {
+	l, err := oplog.Open(filepath.Join(t.TempDir(), "oplog.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		body    io.Reader
+		wantBad bool
+	}{
+		{"no body", nil, false},
+		{"empty chunked body", struct{ io.Reader }{strings.NewReader("")}, false},
+		{"scope", strings.NewReader(`{"scope": "turn"}`), false},
+		{"invalid JSON", strings.NewReader(`{"scope":`), true},
+		{"unknown scope", strings.NewReader(`{"scope": "day"}`), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/undo", tt.body)
+			rec := httptest.NewRecorder()
+			UndoHandler(l)(rec, req)
+			if bad := rec.Code == http.StatusBadRequest; bad != tt.wantBad {
+				t.Errorf("POST /api/undo = %d %s, want bad request %v", rec.Code, rec.Body, tt.wantBad)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package taskmeta stores structured fields that Google Tasks has no room
+// for in a trailing key-value block of the task notes:
+//
+//	Call the venue about the deposit.
+//
+//	---
+//	estimate_minutes: 45
+//	priority: 1
+//	energy: low
+//	goal: Wedding
+//
+// The block starts at the last "---" line and may only contain known keys,
+// so a "---" in the user's own notes is left alone. Older notes with loose
+// "estimate: 45m", "priority: high" or "Start: ..." lines are still read.
+package taskmeta
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const separator = "---"
+
+// Energy levels a task can demand.
+const (
+	EnergyLow    = "low"
+	EnergyMedium = "medium"
+	EnergyHigh   = "high"
+)
+
+// Meta is the structured part of a task. Zero values mean unset.
+type Meta struct {
+	EstimateMinutes int
+	// Priority is 1 for the most important tasks; 0 means unset.
+	Priority int
+	Energy   string
+	Goal     string
+	// Start is when work may begin, RFC3339.
+	Start string
+}
+
+var (
+	legacyEstimate = regexp.MustCompile(`(?im)^\s*estimate(?:_minutes)?\s*:\s*(.+?)\s*$`)
+	legacyPriority = regexp.MustCompile(`(?im)^\s*priority\s*:\s*(.+?)\s*$`)
+	legacyStart    = regexp.MustCompile(`(?im)^\s*start\s*:\s*(.+?)\s*$`)
+)
+
+// Parse splits notes into the free-form body and the metadata.
+func Parse(notes string) (string, Meta) {
+	lines := strings.Split(strings.ReplaceAll(notes, "\r\n", "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != separator {
+			continue
+		}
+		if m, ok := parseBlock(lines[i+1:]); ok {
+			return strings.TrimRight(strings.Join(lines[:i], "\n"), " \n"), m
+		}
+		break
+	}
+	return parseLegacy(lines)
+}
+
+// Format writes body followed by the metadata block. Notes without metadata
+// are just the body.
+func Format(body string, m Meta) string {
+	body = strings.TrimSpace(body)
+	var kv []string
+	if m.EstimateMinutes > 0 {
+		kv = append(kv, fmt.Sprintf("estimate_minutes: %d", m.EstimateMinutes))
+	}
+	if m.Priority > 0 {
+		kv = append(kv, fmt.Sprintf("priority: %d", m.Priority))
+	}
+	if m.Energy != "" {
+		kv = append(kv, "energy: "+m.Energy)
+	}
+	if m.Goal != "" {
+		kv = append(kv, "goal: "+oneLine(m.Goal))
+	}
+	if m.Start != "" {
+		kv = append(kv, "start: "+m.Start)
+	}
+	if len(kv) == 0 {
+		return body
+	}
+
+	block := separator + "\n" + strings.Join(kv, "\n")
+	if body == "" {
+		return block
+	}
+	return body + "\n\n" + block
+}
+
+// IsZero reports whether no field is set.
+func (m Meta) IsZero() bool {
+	return m == Meta{}
+}
+
+// Estimate returns the estimate as a duration, 0 when unset.
+func (m Meta) Estimate() time.Duration {
+	return time.Duration(m.EstimateMinutes) * time.Minute
+}
+
+// Summary renders the set fields for tool output, e.g.
+// "est: 45m | priority: 1 | energy: low".
+func (m Meta) Summary() string {
+	var parts []string
+	if m.EstimateMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("est: %dm", m.EstimateMinutes))
+	}
+	if m.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("priority: %d", m.Priority))
+	}
+	if m.Energy != "" {
+		parts = append(parts, "energy: "+m.Energy)
+	}
+	if m.Goal != "" {
+		parts = append(parts, "goal: "+m.Goal)
+	}
+	if m.Start != "" {
+		parts = append(parts, "start: "+m.Start)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ParseEstimate reads "45", "45m", "90 min", "1.5h" or "2 hours" as minutes.
+func ParseEstimate(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	num := strings.TrimRight(value, "abcdefghijklmnopqrstuvwxyz ")
+	unit := strings.TrimSpace(value[len(num):])
+	amount, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid estimate %q; use minutes like 45 or 45m, or hours like 1.5h", value)
+	}
+	switch unit {
+	case "", "m", "min", "mins", "minute", "minutes":
+		return int(amount + 0.5), nil
+	case "h", "hr", "hrs", "hour", "hours":
+		return int(amount*60 + 0.5), nil
+	}
+	return 0, fmt.Errorf("invalid estimate %q; use minutes like 45 or 45m, or hours like 1.5h", value)
+}
+
+// ParsePriority reads a number (1 is the highest) or high, medium or low.
+func ParsePriority(value string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "high":
+		return 1, nil
+	case "medium":
+		return 2, nil
+	case "low":
+		return 3, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid priority %q; use a number (1 is highest) or high, medium or low", value)
+	}
+	return n, nil
+}
+
+// ParseEnergy normalises an energy level; "" stays unset.
+func ParseEnergy(value string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(value)); v {
+	case "", EnergyLow, EnergyMedium, EnergyHigh:
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid energy %q; use low, medium or high", value)
+}
+
+// parseBlock reads the lines after the separator. ok is false when a line
+// isn't a known key, i.e. the separator belongs to the body.
+func parseBlock(lines []string) (Meta, bool) {
+	var m Meta
+	found := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Meta{}, false
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "estimate_minutes", "estimate":
+			m.EstimateMinutes, _ = ParseEstimate(value)
+		case "priority":
+			m.Priority, _ = ParsePriority(value)
+		case "energy":
+			m.Energy, _ = ParseEnergy(value)
+		case "goal":
+			m.Goal = value
+		case "start":
+			m.Start = value
+		default:
+			return Meta{}, false
+		}
+		found = true
+	}
+	return m, found
+}
+
+// parseLegacy picks up loose metadata lines and drops them from the body so
+// that rewriting the notes moves them into the block.
+func parseLegacy(lines []string) (string, Meta) {
+	var m Meta
+	var body []string
+	for _, line := range lines {
+		if match := legacyEstimate.FindStringSubmatch(line); match != nil && m.EstimateMinutes == 0 {
+			if n, err := ParseEstimate(match[1]); err == nil {
+				m.EstimateMinutes = n
+				continue
+			}
+		}
+		if match := legacyPriority.FindStringSubmatch(line); match != nil && m.Priority == 0 {
+			if n, err := ParsePriority(match[1]); err == nil {
+				m.Priority = n
+				continue
+			}
+		}
+		if match := legacyStart.FindStringSubmatch(line); match != nil && m.Start == "" {
+			m.Start = match[1]
+			continue
+		}
+		body = append(body, line)
+	}
+	return strings.TrimSpace(strings.Join(body, "\n")), m
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,163 @@
+package taskmeta
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		notes    string
+		wantBody string
+		want     Meta
+	}{
+		{
+			name:     "empty",
+			notes:    "",
+			wantBody: "",
+		},
+		{
+			name:     "block",
+			notes:    "Call the venue.\n\n---\nestimate_minutes: 45\npriority: 1\nenergy: low\ngoal: Wedding",
+			wantBody: "Call the venue.",
+			want:     Meta{EstimateMinutes: 45, Priority: 1, Energy: EnergyLow, Goal: "Wedding"},
+		},
+		{
+			name:     "block only",
+			notes:    "---\nstart: 2025-12-10T09:00:00+01:00",
+			wantBody: "",
+			want:     Meta{Start: "2025-12-10T09:00:00+01:00"},
+		},
+		{
+			name:     "user separator is left in the body",
+			notes:    "Intro\n---\nnot: a known key",
+			wantBody: "Intro\n---\nnot: a known key",
+		},
+		{
+			name:     "last separator wins",
+			notes:    "Intro\n---\nmore text\n\n---\npriority: high\r\n",
+			wantBody: "Intro\n---\nmore text",
+			want:     Meta{Priority: 1},
+		},
+		{
+			name:     "legacy lines",
+			notes:    "Book tickets\nestimate: 1.5h\nPriority: low\nStart: tomorrow",
+			wantBody: "Book tickets",
+			want:     Meta{EstimateMinutes: 90, Priority: 3, Start: "tomorrow"},
+		},
+		{
+			name:     "legacy line with an invalid value stays in the body",
+			notes:    "estimate: soon",
+			wantBody: "estimate: soon",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, m := Parse(tt.notes)
+			if body != tt.wantBody || m != tt.want {
+				t.Errorf("Parse(%q) = %q, %+v; want %q, %+v", tt.notes, body, m, tt.wantBody, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		body string
+		meta Meta
+		want string
+	}{
+		{"  Just notes \n", Meta{}, "Just notes"},
+		{"", Meta{Priority: 2}, "---\npriority: 2"},
+		{
+			"Call the venue.",
+			Meta{EstimateMinutes: 45, Priority: 1, Energy: EnergyLow, Goal: "Big\n  day", Start: "2025-12-10"},
+			"Call the venue.\n\n---\nestimate_minutes: 45\npriority: 1\nenergy: low\ngoal: Big day\nstart: 2025-12-10",
+		},
+	}
+	for _, tt := range tests {
+		got := Format(tt.body, tt.meta)
+		if got != tt.want {
+			t.Errorf("Format(%q, %+v) = %q, want %q", tt.body, tt.meta, got, tt.want)
+		}
+		body, m := Parse(got)
+		want := tt.meta
+		want.Goal = oneLine(want.Goal)
+		if m != want || body != Format(tt.body, Meta{}) {
+			t.Errorf("Parse(Format(...)) = %q, %+v; want round trip of %+v", body, m, want)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	m := Meta{EstimateMinutes: 45, Priority: 1, Energy: EnergyLow}
+	if got, want := m.Summary(), "est: 45m | priority: 1 | energy: low"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if got := (Meta{}).Summary(); got != "" {
+		t.Errorf("empty Summary() = %q", got)
+	}
+}
+
+func TestParseEstimate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"45", 45, false},
+		{"45m", 45, false},
+		{"90 min", 90, false},
+		{"1.5h", 90, false},
+		{"2 hours", 120, false},
+		{" 30 Minutes ", 30, false},
+		{"", 0, true},
+		{"soon", 0, true},
+		{"3 days", 0, true},
+		{"-5m", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEstimate(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEstimate(%q) = %d, %v; want %d, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"1", 1, false},
+		{"High", 1, false},
+		{"medium", 2, false},
+		{"low", 3, false},
+		{"urgent", 0, true},
+		{"-1", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePriority(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParsePriority(%q) = %d, %v; want %d, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseEnergy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"LOW", EnergyLow, false},
+		{" high ", EnergyHigh, false},
+		{"tired", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEnergy(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEnergy(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
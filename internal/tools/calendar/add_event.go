@@ -7,21 +7,26 @@ import (
 	"strings"
 	"time"
 
-	"google.golang.org/api/calendar/v3"
+	"groundhog/internal/changes"
+	"groundhog/internal/nldate"
 
 	"github.com/tmc/langchaingo/tools"
 )
 
-// AddEvent creates new events in the user's Google Calendar.
+// AddEvent creates new events in the user's calendar and keeps travel time
+// to neighbouring events at other locations according to its TravelPolicy.
 type AddEvent struct {
-	credFile string
+	provider CalendarProvider
+	travel   *TravelPolicy
 }
 
 var _ tools.Tool = &AddEvent{}
 
-func NewAddEvent(credFile string) *AddEvent {
+// NewAddEvent returns the tool. travel may be nil to skip travel handling.
+func NewAddEvent(provider CalendarProvider, travel *TravelPolicy) *AddEvent {
 	return &AddEvent{
-		credFile: credFile,
+		provider: provider,
+		travel:   travel,
 	}
 }
 
@@ -30,7 +35,7 @@ func (a *AddEvent) Name() string {
 }
 
 func (a *AddEvent) Description() string {
-	return `Add a new event to the user's Google Calendar.
+	return `Add a new event to the user's calendar.
 
 Input must be a stringified JSON object like:
 {
@@ -40,17 +45,21 @@ Input must be a stringified JSON object like:
   "duration_minutes": 30,
   "description": "Discuss project status",
   "location": "Zoom",
-  "time_zone": "America/New_York"
+  "time_zone": "America/New_York",
+  "focus_block": false,
+  "ignore_availability": false
 }
 
 Fields:
 - summary (string, required): event title.
-- start_time (string, required): RFC3339 timestamp or YYYY-MM-DD for all-day events.
+- start_time (string, required): RFC3339 timestamp or YYYY-MM-DD for all-day events. Relative expressions like "tomorrow 3pm" or "next Friday morning" are also accepted.
 - end_time (string, optional): RFC3339 timestamp; omit when using duration_minutes.
 - duration_minutes (integer, optional): length in minutes when end_time is omitted.
 - description (string, optional)
 - location (string, optional)
-- time_zone (string, optional): IANA name, e.g., "America/New_York". `
+- time_zone (string, optional): IANA name, e.g., "America/New_York". Defaults to the user's time zone.
+- focus_block (boolean, optional): true for solo work blocks such as those from schedule_tasks; they may go into no-meeting blocks.
+- ignore_availability (boolean, optional): book even outside the user's availability; only when the user explicitly asks. `
 }
 
 // Parameters exposes the structured schema for tool calling.
@@ -64,7 +73,7 @@ func (a *AddEvent) Parameters() map[string]interface{} {
 			},
 			"start_time": map[string]interface{}{
 				"type":        "string",
-				"description": "RFC3339 timestamp or YYYY-MM-DD for all-day events (required).",
+				"description": "RFC3339 timestamp, YYYY-MM-DD for all-day events, or a relative expression like \"tomorrow 3pm\" (required).",
 			},
 			"end_time": map[string]interface{}{
 				"type":        "string",
@@ -84,7 +93,15 @@ func (a *AddEvent) Parameters() map[string]interface{} {
 			},
 			"time_zone": map[string]interface{}{
 				"type":        "string",
-				"description": "IANA time zone, e.g., America/New_York.",
+				"description": "IANA time zone, e.g., America/New_York. Defaults to the user's time zone.",
+			},
+			"focus_block": map[string]interface{}{
+				"type":        "boolean",
+				"description": "True for solo work blocks, which may use no-meeting time.",
+			},
+			"ignore_availability": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Book outside the user's availability; only when the user explicitly asks.",
 			},
 		},
 		"required": []string{"summary", "start_time"},
@@ -101,68 +118,95 @@ func (a *AddEvent) Call(ctx context.Context, input string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	srv, err := newCalendarService(ctx, a.credFile)
-	if err != nil {
-		return "", err
+	if strings.TrimSpace(payload.TimeZone) == "" {
+		payload.TimeZone = userTimeZone(ctx)
 	}
 
 	start, end, allDay, tz, err := prepareEventTimes(payload)
 	if err != nil {
 		return "", err
 	}
+	if !allDay && !payload.IgnoreAvailability {
+		if conflict := availabilityConflict(ctx, payload.Summary, start, end, !payload.FocusBlock); conflict != "" {
+			return conflict, nil
+		}
+	}
 
-	event := &calendar.Event{
+	created, err := a.provider.InsertEvent(ctx, &Event{
 		Summary:     payload.Summary,
 		Description: payload.Description,
 		Location:    payload.Location,
+		Start:       start,
+		End:         end,
+		AllDay:      allDay,
+		TimeZone:    tz,
+	})
+	if err != nil {
+		return "", err
 	}
+	recordCreate(ctx, created)
 
-	if allDay {
-		event.Start = &calendar.EventDateTime{
-			Date: start.Format(time.DateOnly),
-		}
-		event.End = &calendar.EventDateTime{
-			Date: end.Format(time.DateOnly),
-		}
-	} else {
-		event.Start = &calendar.EventDateTime{
-			DateTime: start.Format(time.RFC3339),
-			TimeZone: tz,
-		}
-		event.End = &calendar.EventDateTime{
-			DateTime: end.Format(time.RFC3339),
-			TimeZone: tz,
-		}
+	startDisplay := formatEventTime(localEventTime(ctx, created.Start, created.AllDay), created.AllDay)
+	if startDisplay == "" {
+		startDisplay = payload.StartTime
+	}
+	endDisplay := formatEventTime(localEventTime(ctx, created.End, created.AllDay), created.AllDay)
+	if endDisplay == "" {
+		endDisplay = payload.EndTime
 	}
 
-	insertCall := srv.Events.Insert("primary", event).Context(ctx)
-	created, err := insertCall.Do()
-	if err != nil {
-		return "", fmt.Errorf("unable to create event: %w", err)
+	res := fmt.Sprintf("Created calendar event \"%s\" (%s → %s).", created.Summary, startDisplay, endDisplay)
+	if created.HTMLLink != "" {
+		res = fmt.Sprintf("Created calendar event \"%s\" (%s → %s). Link: %s", created.Summary, startDisplay, endDisplay, created.HTMLLink)
 	}
 
-	startDisplay := payload.StartTime
-	if created.Start != nil {
-		if created.Start.DateTime != "" {
-			startDisplay = created.Start.DateTime
-		} else if created.Start.Date != "" {
-			startDisplay = created.Start.Date
-		}
+	for _, line := range a.travel.travelNotes(ctx, a.provider, *created) {
+		res += "\n" + line
+	}
+	return res, nil
+}
+
+// Describe validates input and summarises the event it would create.
+func (a *AddEvent) Describe(ctx context.Context, input string) (string, error) {
+	payload, err := parseAddEventInput(input)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(payload.TimeZone) == "" {
+		payload.TimeZone = userTimeZone(ctx)
 	}
-	endDisplay := payload.EndTime
-	if created.End != nil {
-		if created.End.DateTime != "" {
-			endDisplay = created.End.DateTime
-		} else if created.End.Date != "" {
-			endDisplay = created.End.Date
+	start, end, allDay, _, err := prepareEventTimes(payload)
+	if err != nil {
+		return "", err
+	}
+	if !allDay && !payload.IgnoreAvailability {
+		if conflict := availabilityConflict(ctx, payload.Summary, start, end, !payload.FocusBlock); conflict != "" {
+			return "", changes.Refuse(conflict)
 		}
 	}
+	return fmt.Sprintf("Create event \"%s\" (%s → %s)", payload.Summary,
+		formatEventTime(localEventTime(ctx, start, allDay), allDay),
+		formatEventTime(localEventTime(ctx, end, allDay), allDay)), nil
+}
 
-	if created.HtmlLink != "" {
-		return fmt.Sprintf("Created calendar event \"%s\" (%s → %s). Link: %s", created.Summary, startDisplay, endDisplay, created.HtmlLink), nil
+// Resolve makes relative start and end times absolute.
+func (a *AddEvent) Resolve(ctx context.Context, input string) (string, error) {
+	payload, err := parseAddEventInput(input)
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("Created calendar event \"%s\" (%s → %s).", created.Summary, startDisplay, endDisplay), nil
+	tz := payload.TimeZone
+	if strings.TrimSpace(tz) == "" {
+		tz = userTimeZone(ensureContext(ctx))
+	}
+	if payload.StartTime, err = resolveTime(payload.StartTime, tz); err != nil {
+		return "", fmt.Errorf("invalid start_time: %w", err)
+	}
+	if payload.EndTime, err = resolveTime(payload.EndTime, tz); err != nil {
+		return "", fmt.Errorf("invalid end_time: %w", err)
+	}
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
 }
 
 type addEventInput struct {
@@ -173,6 +217,9 @@ type addEventInput struct {
 	DurationMinutes int    `json:"duration_minutes,omitempty"`
 	TimeZone        string `json:"time_zone,omitempty"`
 	Location        string `json:"location,omitempty"`
+	// FocusBlock marks solo work that may use no-meeting time.
+	FocusBlock         bool `json:"focus_block,omitempty"`
+	IgnoreAvailability bool `json:"ignore_availability,omitempty"`
 }
 
 func parseAddEventInput(raw string) (addEventInput, error) {
@@ -260,7 +307,38 @@ func parseTime(value, timeZone string) (time.Time, bool, error) {
 		return t, false, nil
 	}
 
-	return time.Time{}, false, fmt.Errorf("could not parse time %q; use RFC3339, YYYY-MM-DD for all-day, YYYY-MM-DDTHH:MM[:SS], or YYYY-MM-DD HH:MM", value)
+	if t, dateOnly, err := nldate.Parse(value, time.Now().In(loc)); err == nil {
+		if dateOnly {
+			// Match time.Parse(time.DateOnly, ...) above so all-day values compare consistently.
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true, nil
+		}
+		return t, false, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("could not parse time %q; use RFC3339, YYYY-MM-DD for all-day, YYYY-MM-DDTHH:MM[:SS], YYYY-MM-DD HH:MM, or an expression like \"tomorrow 3pm\" or \"in 2 hours\"", value)
+}
+
+// resolveTime rewrites a relative value such as "in 2 hours" as RFC3339, or
+// YYYY-MM-DD for a day, so it no longer depends on when it is read. Empty and
+// absolute values are returned unchanged.
+func resolveTime(value, timeZone string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return value, nil
+		}
+	}
+	t, allDay, err := parseTime(value, timeZone)
+	if err != nil {
+		return "", err
+	}
+	if allDay {
+		return t.Format(time.DateOnly), nil
+	}
+	return t.Format(time.RFC3339), nil
 }
 
 func validateAddEventInput(payload addEventInput) (addEventInput, error) {
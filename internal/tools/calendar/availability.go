@@ -0,0 +1,53 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"groundhog/internal/profile"
+)
+
+// availabilityConflict explains why start–end breaks the user's availability,
+// or returns "" when it doesn't. meeting enables the no-meeting blocks.
+func availabilityConflict(ctx context.Context, summary string, start, end time.Time, meeting bool) string {
+	violations := availabilityViolations(ctx, summary, start, end, meeting)
+	if len(violations) == 0 {
+		return ""
+	}
+	loc := profile.Location(ctx)
+	return fmt.Sprintf("Not scheduled: \"%s\" at %s → %s conflicts with the user's availability (%s). Suggest another time, or retry with ignore_availability set to true if the user explicitly wants this time.",
+		summary, start.In(loc).Format(time.RFC3339), end.In(loc).Format(time.RFC3339), strings.Join(violations, "; "))
+}
+
+// availabilityViolations lists the availability rules start–end breaks.
+// Events about lunch may use the lunch window.
+func availabilityViolations(ctx context.Context, summary string, start, end time.Time, meeting bool) []string {
+	p := profile.FromContext(ctx)
+	violations := p.Availability.Violations(start, end, p.Location(), meeting)
+	if strings.Contains(strings.ToLower(summary), "lunch") {
+		violations = dropLabel(violations, "lunch")
+	}
+	return violations
+}
+
+func dropLabel(violations []string, label string) []string {
+	var res []string
+	for _, v := range violations {
+		if !strings.Contains(v, label) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// isMeeting reports whether anyone besides the user is invited.
+func isMeeting(e Event) bool {
+	for _, a := range e.Attendees {
+		if !a.Self {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// cacheMaxAge is how long cached events are served before the next read
+	// runs an incremental sync.
+	cacheMaxAge = 30 * time.Second
+	// cacheHorizon is how far back the initial full sync reaches. Reads that
+	// start earlier go straight to the provider.
+	cacheHorizon = 30 * 24 * time.Hour
+	// cacheLookahead is how far ahead the full sync reaches, so recurring
+	// series without an end expand to a bounded number of instances. Reads
+	// that end later go straight to the provider.
+	cacheLookahead = 365 * 24 * time.Hour
+)
+
+// ErrSyncTokenExpired is returned by a syncer when the server no longer
+// accepts the sync token and a full sync is needed.
+var ErrSyncTokenExpired = errors.New("sync token expired")
+
+// syncer is implemented by providers that support incremental sync.
+type syncer interface {
+	// SyncEvents returns the events changed and the ids removed since token.
+	// An empty token requests a full sync of the events between since and
+	// until.
+	SyncEvents(ctx context.Context, token string, since, until time.Time) (changed []Event, removed []string, next string, err error)
+}
+
+// CachedProvider serves ListEvents from a per-user in-memory copy of the
+// calendar that is kept current with incremental sync. Our own mutations
+// invalidate the copy so the next read picks them up. Providers that cannot
+// sync incrementally are passed through untouched.
+type CachedProvider struct {
+	CalendarProvider
+
+	mu    sync.Mutex
+	users map[string]*eventCache
+}
+
+var _ CalendarProvider = &CachedProvider{}
+
+type eventCache struct {
+	mu        sync.Mutex
+	events    map[string]Event
+	horizon   time.Time
+	until     time.Time
+	syncToken string
+	syncedAt  time.Time
+	stale     bool
+}
+
+func NewCachedProvider(provider CalendarProvider) *CachedProvider {
+	return &CachedProvider{
+		CalendarProvider: provider,
+		users:            make(map[string]*eventCache),
+	}
+}
+
+func (c *CachedProvider) ListEvents(ctx context.Context, from, to time.Time) ([]Event, error) {
+	s, ok := c.CalendarProvider.(syncer)
+	if !ok {
+		return c.CalendarProvider.ListEvents(ctx, from, to)
+	}
+
+	cache := c.userCache(ctx)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if !cache.horizon.IsZero() && (from.Before(cache.horizon) || to.After(cache.until)) {
+		return c.CalendarProvider.ListEvents(ctx, from, to)
+	}
+	if err := cache.refresh(ctx, s); err != nil {
+		return nil, err
+	}
+	if from.Before(cache.horizon) || to.After(cache.until) {
+		return c.CalendarProvider.ListEvents(ctx, from, to)
+	}
+
+	var res []Event
+	for _, e := range cache.events {
+		if e.End.After(from) && e.Start.Before(to) {
+			res = append(res, e)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Start.Equal(res[j].Start) {
+			return res[i].ID < res[j].ID
+		}
+		return res[i].Start.Before(res[j].Start)
+	})
+	return res, nil
+}
+
+func (c *CachedProvider) InsertEvent(ctx context.Context, event *Event) (*Event, error) {
+	defer c.Invalidate(ctx)
+	return c.CalendarProvider.InsertEvent(ctx, event)
+}
+
+func (c *CachedProvider) UpdateEvent(ctx context.Context, event *Event) (*Event, error) {
+	defer c.Invalidate(ctx)
+	return c.CalendarProvider.UpdateEvent(ctx, event)
+}
+
+func (c *CachedProvider) DeleteEvent(ctx context.Context, id string) error {
+	defer c.Invalidate(ctx)
+	return c.CalendarProvider.DeleteEvent(ctx, id)
+}
+
+func (c *CachedProvider) RespondToEvent(ctx context.Context, id, response, comment string) (*Event, error) {
+	defer c.Invalidate(ctx)
+	return c.CalendarProvider.RespondToEvent(ctx, id, response, comment)
+}
+
+// Invalidate makes the next read of the current user sync before answering.
+func (c *CachedProvider) Invalidate(ctx context.Context) {
+	cache := c.userCache(ctx)
+	cache.mu.Lock()
+	cache.stale = true
+	cache.mu.Unlock()
+}
+
+func (c *CachedProvider) userCache(ctx context.Context) *eventCache {
+	userID, _ := ensureContext(ctx).Value("UserID").(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cache, ok := c.users[userID]
+	if !ok {
+		cache = &eventCache{}
+		c.users[userID] = cache
+	}
+	return cache
+}
+
+// refresh brings the cache up to date. The caller holds cache.mu.
+func (e *eventCache) refresh(ctx context.Context, s syncer) error {
+	if e.syncToken != "" && !e.stale && time.Since(e.syncedAt) < cacheMaxAge {
+		return nil
+	}
+
+	// The window moves with time; a full sync renews it once half of the
+	// lookahead has passed.
+	if e.syncToken != "" && time.Until(e.until) > cacheLookahead/2 {
+		changed, removed, next, err := s.SyncEvents(ctx, e.syncToken, e.horizon, e.until)
+		switch {
+		case err == nil:
+			for _, id := range removed {
+				delete(e.events, id)
+			}
+			for _, ev := range changed {
+				e.events[ev.ID] = ev
+			}
+			e.syncToken, e.syncedAt, e.stale = next, time.Now(), false
+			return nil
+		case !errors.Is(err, ErrSyncTokenExpired):
+			return err
+		}
+	}
+
+	now := time.Now()
+	horizon, until := now.Add(-cacheHorizon), now.Add(cacheLookahead)
+	changed, _, next, err := s.SyncEvents(ctx, "", horizon, until)
+	if err != nil {
+		return err
+	}
+	e.events = make(map[string]Event, len(changed))
+	for _, ev := range changed {
+		e.events[ev.ID] = ev
+	}
+	e.horizon, e.until, e.syncToken, e.syncedAt, e.stale = horizon, until, next, time.Now(), false
+	return nil
+}
@@ -0,0 +1,589 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"groundhog/internal/ical"
+	"groundhog/internal/profile"
+)
+
+const (
+	immovableICalProperty = "X-GROUNDHOG-IMMOVABLE"
+	caldavTimeLayout      = "20060102T150405Z"
+)
+
+// CalDAVProvider stores events in a CalDAV calendar collection such as a
+// Nextcloud or Radicale calendar. Event IDs are the resource paths on the
+// server; expanded instances of recurring events get "#RECURRENCE-ID" appended.
+type CalDAVProvider struct {
+	collection *url.URL
+	username   string
+	password   string
+	email      string
+
+	// HTTPClient is used for every request; it defaults to http.DefaultClient
+	// and can be replaced to point the provider at a local stand-in server.
+	HTTPClient *http.Client
+}
+
+var _ CalendarProvider = &CalDAVProvider{}
+
+// NewCalDAVProvider returns a provider for the calendar collection at
+// collectionURL. email identifies the user among attendees.
+func NewCalDAVProvider(collectionURL, username, password, email string) *CalDAVProvider {
+	u, err := url.Parse(strings.TrimSpace(collectionURL))
+	if err != nil {
+		u = &url.URL{}
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &CalDAVProvider{
+		collection: u,
+		username:   username,
+		password:   password,
+		email:      strings.TrimSpace(email),
+	}
+}
+
+func (c *CalDAVProvider) ListEvents(ctx context.Context, from, to time.Time) ([]Event, error) {
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data><C:expand start="%[1]s" end="%[2]s"/></C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT"><C:time-range start="%[1]s" end="%[2]s"/></C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`, from.UTC().Format(caldavTimeLayout), to.UTC().Format(caldavTimeLayout))
+
+	objects, err := c.report(ctx, body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve events: %w", err)
+	}
+
+	var res []Event
+	for _, obj := range objects {
+		for _, e := range c.eventsFromObject(ctx, obj) {
+			if e.End.After(from) && e.Start.Before(to) {
+				res = append(res, e)
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Start.Before(res[j].Start)
+	})
+	return res, nil
+}
+
+// HasUID reports whether an event with the iCalendar UID exists, at any
+// date.
+func (c *CalDAVProvider) HasUID(ctx context.Context, uid string) (bool, error) {
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data/>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:prop-filter name="UID"><C:text-match collation="i;octet">%s</C:text-match></C:prop-filter>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`, xmlEscape(uid))
+
+	objects, err := c.report(ctx, body)
+	if err != nil {
+		return false, fmt.Errorf("unable to look up event %q: %w", uid, err)
+	}
+	return len(objects) > 0, nil
+}
+
+func (c *CalDAVProvider) GetEvent(ctx context.Context, id string) (*Event, error) {
+	href, recurrenceID, _ := strings.Cut(id, "#")
+	if recurrenceID != "" {
+		return c.getInstance(ctx, href, recurrenceID)
+	}
+
+	obj, err := c.get(ctx, href)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch event %q: %w", id, err)
+	}
+	events := c.eventsFromObject(ctx, obj)
+	if len(events) == 0 {
+		return nil, fmt.Errorf("unable to fetch event %q: resource has no VEVENT", id)
+	}
+	return &events[0], nil
+}
+
+func (c *CalDAVProvider) InsertEvent(ctx context.Context, event *Event) (*Event, error) {
+	uid := event.UID
+	if uid == "" {
+		var err error
+		if uid, err = newUID(); err != nil {
+			return nil, fmt.Errorf("unable to create event: %w", err)
+		}
+	}
+	href := c.collection.JoinPath(url.PathEscape(uid) + ".ics").Path
+
+	vevent := ical.NewEvent(c.toICalEvent(event, uid))
+	if event.Immovable {
+		vevent.Set(ical.Property{Name: immovableICalProperty, Value: "TRUE"})
+	}
+	cal := ical.NewCalendar(vevent)
+	if _, err := c.put(ctx, href, cal, "", true); err != nil {
+		return nil, fmt.Errorf("unable to create event: %w", err)
+	}
+	return c.GetEvent(ctx, href)
+}
+
+// CanUpdate reports whether UpdateEvent can save e; single occurrences of
+// recurring events can't be edited on their own.
+func (c *CalDAVProvider) CanUpdate(e Event) bool {
+	_, recurrenceID, _ := strings.Cut(e.ID, "#")
+	return recurrenceID == ""
+}
+
+func (c *CalDAVProvider) UpdateEvent(ctx context.Context, event *Event) (*Event, error) {
+	href, recurrenceID, _ := strings.Cut(event.ID, "#")
+	if recurrenceID != "" {
+		return nil, fmt.Errorf("unable to update event: editing a single occurrence of a recurring event is not supported by the CalDAV provider")
+	}
+
+	obj, err := c.get(ctx, href)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch event %q: %w", event.ID, err)
+	}
+	master := masterEvent(obj.calendar)
+	if master == nil {
+		return nil, fmt.Errorf("unable to update event: resource %q has no VEVENT", href)
+	}
+	c.toICalEvent(event, master.Value("UID")).Apply(master)
+	if event.Immovable {
+		master.Set(ical.Property{Name: immovableICalProperty, Value: "TRUE"})
+	}
+
+	etag := obj.etag
+	if event.ETag != "" {
+		etag = event.ETag
+	}
+	if _, err := c.put(ctx, href, obj.calendar, etag, false); err != nil {
+		return nil, fmt.Errorf("unable to update event: %w", err)
+	}
+	return c.GetEvent(ctx, href)
+}
+
+func (c *CalDAVProvider) DeleteEvent(ctx context.Context, id string) error {
+	href, recurrenceID, _ := strings.Cut(id, "#")
+	if recurrenceID != "" {
+		return fmt.Errorf("unable to delete event: deleting a single occurrence of a recurring event is not supported by the CalDAV provider")
+	}
+
+	req, err := c.newRequest(ctx, http.MethodDelete, href, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to delete event %q: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to delete event %q: %w", id, caldavError(resp))
+	}
+	return nil
+}
+
+// RespondToEvent updates the PARTSTAT of the user's ATTENDEE line. The server
+// is expected to deliver the reply to the organizer (RFC 6638).
+func (c *CalDAVProvider) RespondToEvent(ctx context.Context, id, response, comment string) (*Event, error) {
+	href, recurrenceID, _ := strings.Cut(id, "#")
+	if recurrenceID != "" {
+		return nil, fmt.Errorf("unable to respond to event: answering a single occurrence of a recurring event is not supported by the CalDAV provider")
+	}
+
+	obj, err := c.get(ctx, href)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch event %q: %w", id, err)
+	}
+	master := masterEvent(obj.calendar)
+	if master == nil {
+		return nil, fmt.Errorf("unable to respond to event: resource %q has no VEVENT", href)
+	}
+
+	found := false
+	for i, p := range master.Props {
+		if p.Name != "ATTENDEE" || !c.isSelf(strings.TrimPrefix(strings.ToLower(p.Value), "mailto:")) {
+			continue
+		}
+		params := make(map[string]string, len(p.Params)+1)
+		for k, v := range p.Params {
+			params[k] = v
+		}
+		params["PARTSTAT"] = toPartStat(response)
+		delete(params, "RSVP")
+		master.Props[i].Params = params
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("unable to respond to event %q: you are not an attendee", id)
+	}
+	if comment != "" {
+		master.Set(ical.Property{Name: "COMMENT", Value: ical.EscapeText(comment)})
+	} else {
+		master.Del("COMMENT")
+	}
+
+	if _, err := c.put(ctx, href, obj.calendar, obj.etag, false); err != nil {
+		return nil, fmt.Errorf("unable to respond to event: %w", err)
+	}
+	return c.GetEvent(ctx, href)
+}
+
+func (c *CalDAVProvider) toICalEvent(e *Event, uid string) ical.Event {
+	out := ical.Event{
+		UID:         uid,
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+		Start:       e.Start,
+		End:         e.End,
+		AllDay:      e.AllDay,
+	}
+	if e.Organizer != nil {
+		out.Organizer = &ical.Attendee{Email: e.Organizer.Email, Name: e.Organizer.Name}
+	}
+	for _, a := range e.Attendees {
+		// Without ROLE an attendee is required, and an existing CHAIR stays.
+		role := ""
+		if a.Optional {
+			role = "OPT-PARTICIPANT"
+		}
+		out.Attendees = append(out.Attendees, ical.Attendee{
+			Email:    a.Email,
+			Name:     a.Name,
+			PartStat: toPartStat(a.ResponseStatus),
+			Role:     role,
+		})
+	}
+	return out
+}
+
+// caldavObject is a calendar resource as returned by the server.
+type caldavObject struct {
+	href     string
+	etag     string
+	calendar *ical.Component
+}
+
+// eventsFromObject reads the VEVENTs of obj; floating times are in the user's
+// zone.
+func (c *CalDAVProvider) eventsFromObject(ctx context.Context, obj caldavObject) []Event {
+	var res []Event
+	for _, vevent := range obj.calendar.Children("VEVENT") {
+		parsed, err := ical.ParseEvent(vevent, profile.Location(ctx))
+		if err != nil {
+			continue
+		}
+		e := Event{
+			ID:          obj.href,
+			UID:         parsed.UID,
+			Calendar:    c.collection.Path,
+			Summary:     parsed.Summary,
+			Description: parsed.Description,
+			Location:    parsed.Location,
+			Status:      strings.ToLower(parsed.Status),
+			HTMLLink:    parsed.URL,
+			Start:       parsed.Start,
+			End:         parsed.End,
+			AllDay:      parsed.AllDay,
+			TimeZone:    parsed.TimeZone,
+			Immovable:   strings.EqualFold(vevent.Value(immovableICalProperty), "TRUE"),
+			ETag:        obj.etag,
+		}
+		if parsed.RecurrenceID != "" {
+			e.ID = obj.href + "#" + parsed.RecurrenceID
+		}
+		e.Recurring = parsed.RecurrenceID != "" || vevent.Value("RRULE") != ""
+		// CONFERENCE is RFC 7986; Google exports use X-GOOGLE-CONFERENCE.
+		e.ConferenceURL = vevent.Value("CONFERENCE")
+		if e.ConferenceURL == "" {
+			e.ConferenceURL = vevent.Value("X-GOOGLE-CONFERENCE")
+		}
+		if parsed.Organizer != nil {
+			e.Organizer = &Attendee{
+				Email: parsed.Organizer.Email,
+				Name:  parsed.Organizer.Name,
+				Self:  c.isSelf(parsed.Organizer.Email),
+			}
+		}
+		for _, a := range parsed.Attendees {
+			attendee := Attendee{
+				Email:          a.Email,
+				Name:           a.Name,
+				ResponseStatus: fromPartStat(a.PartStat),
+				Self:           c.isSelf(a.Email),
+				Optional:       a.Role == "OPT-PARTICIPANT",
+			}
+			if attendee.Self {
+				attendee.Comment = ical.UnescapeText(vevent.Value("COMMENT"))
+			}
+			e.Attendees = append(e.Attendees, attendee)
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+func (c *CalDAVProvider) isSelf(email string) bool {
+	return c.email != "" && strings.EqualFold(strings.TrimSpace(email), c.email)
+}
+
+// getInstance fetches one expanded occurrence of a recurring event.
+func (c *CalDAVProvider) getInstance(ctx context.Context, href, recurrenceID string) (*Event, error) {
+	rid, _, err := ical.ParseDateTime(ical.Property{Name: "RECURRENCE-ID", Value: recurrenceID}, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event id %q: %w", href+"#"+recurrenceID, err)
+	}
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data><C:expand start="%s" end="%s"/></C:calendar-data>
+  </D:prop>
+  <D:href>%s</D:href>
+</C:calendar-multiget>`,
+		rid.AddDate(0, 0, -7).UTC().Format(caldavTimeLayout),
+		rid.AddDate(0, 0, 7).UTC().Format(caldavTimeLayout),
+		xmlEscape(href))
+
+	objects, err := c.report(ctx, body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch event %q: %w", href, err)
+	}
+	id := href + "#" + recurrenceID
+	for _, obj := range objects {
+		for _, e := range c.eventsFromObject(ctx, obj) {
+			if e.ID == id {
+				return &e, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unable to fetch event %q: occurrence not found", id)
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag         string `xml:"DAV: getetag"`
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func (c *CalDAVProvider) report(ctx context.Context, body string) ([]caldavObject, error) {
+	req, err := c.newRequest(ctx, "REPORT", c.collection.Path, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, caldavError(resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("invalid multistatus response: %w", err)
+	}
+
+	var objects []caldavObject
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.CalendarData == "" || (ps.Status != "" && !strings.Contains(ps.Status, " 200 ")) {
+				continue
+			}
+			cal, err := decodeCalendar(strings.NewReader(ps.Prop.CalendarData))
+			if err != nil {
+				continue
+			}
+			objects = append(objects, caldavObject{href: hrefPath(r.Href), etag: ps.Prop.ETag, calendar: cal})
+		}
+	}
+	return objects, nil
+}
+
+func (c *CalDAVProvider) get(ctx context.Context, href string) (caldavObject, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, href, nil)
+	if err != nil {
+		return caldavObject{}, err
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return caldavObject{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return caldavObject{}, caldavError(resp)
+	}
+	cal, err := decodeCalendar(resp.Body)
+	if err != nil {
+		return caldavObject{}, err
+	}
+	return caldavObject{href: href, etag: resp.Header.Get("ETag"), calendar: cal}, nil
+}
+
+// put uploads a calendar resource. etag makes the write conditional on the
+// resource being unchanged; create makes it fail if the resource exists.
+func (c *CalDAVProvider) put(ctx context.Context, href string, cal *ical.Component, etag string, create bool) (string, error) {
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return "", err
+	}
+	req, err := c.newRequest(ctx, http.MethodPut, href, &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	if create {
+		req.Header.Set("If-None-Match", "*")
+	} else if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed && !create {
+		return "", ErrPreconditionFailed
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return "", caldavError(resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *CalDAVProvider) newRequest(ctx context.Context, method, href string, body io.Reader) (*http.Request, error) {
+	target := c.collection.ResolveReference(&url.URL{Path: href})
+	req, err := http.NewRequestWithContext(ensureContext(ctx), method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+func (c *CalDAVProvider) client() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func caldavError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("caldav server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func decodeCalendar(r io.Reader) (*ical.Component, error) {
+	roots, err := ical.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		if root.Name == "VCALENDAR" {
+			return root, nil
+		}
+	}
+	return nil, fmt.Errorf("no VCALENDAR found")
+}
+
+// masterEvent returns the VEVENT that carries the series definition, i.e. the
+// one without RECURRENCE-ID.
+func masterEvent(cal *ical.Component) *ical.Component {
+	for _, vevent := range cal.Children("VEVENT") {
+		if vevent.Value("RECURRENCE-ID") == "" {
+			return vevent
+		}
+	}
+	return nil
+}
+
+func hrefPath(href string) string {
+	if u, err := url.Parse(strings.TrimSpace(href)); err == nil {
+		return u.Path
+	}
+	return strings.TrimSpace(href)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func newUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf) + "@groundhog", nil
+}
+
+func toPartStat(responseStatus string) string {
+	switch responseStatus {
+	case "accepted":
+		return "ACCEPTED"
+	case "declined":
+		return "DECLINED"
+	case "tentative":
+		return "TENTATIVE"
+	case "":
+		return ""
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func fromPartStat(partStat string) string {
+	switch strings.ToUpper(partStat) {
+	case "ACCEPTED":
+		return "accepted"
+	case "DECLINED":
+		return "declined"
+	case "TENTATIVE":
+		return "tentative"
+	default:
+		return "needsAction"
+	}
+}
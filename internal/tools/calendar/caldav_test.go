@@ -0,0 +1,154 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const caldavFixture = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//Test//EN
+BEGIN:VEVENT
+UID:standup@example.com
+DTSTAMP:20251201T080000Z
+DTSTART:20251209T090000Z
+DTEND:20251209T093000Z
+SUMMARY:Standup
+ORGANIZER;CN=Ann;SENT-BY="mailto:assistant@example.com":mailto:ann@example.com
+ATTENDEE;CN=Bob;PARTSTAT=ACCEPTED;RSVP=TRUE;ROLE=CHAIR:mailto:bob@example.com
+ATTENDEE;CN=Cy;PARTSTAT=NEEDS-ACTION;RSVP=TRUE;CUTYPE=INDIVIDUAL:mailto:cy@example.com
+X-VENDOR-FLAG:kept
+BEGIN:VALARM
+ACTION:DISPLAY
+TRIGGER:-PT10M
+END:VALARM
+END:VEVENT
+END:VCALENDAR
+`
+
+// fakeCalDAV stores resources in memory and honours If-Match like a CalDAV
+// server.
+type fakeCalDAV struct {
+	mu        sync.Mutex
+	resources map[string]string
+	etags     map[string]int
+}
+
+func (f *fakeCalDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, ok := f.resources[r.URL.Path]
+	etag := fmt.Sprintf(`"%d"`, f.etags[r.URL.Path])
+	switch r.Method {
+	case http.MethodGet:
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		io.WriteString(w, body)
+	case http.MethodPut:
+		if match := r.Header.Get("If-Match"); match != "" && match != etag {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.resources[r.URL.Path] = string(data)
+		f.etags[r.URL.Path]++
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, f.etags[r.URL.Path]))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestCalDAVUpdateEventRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(e *Event)
+		stale   bool
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "rename keeps participants and unknown properties",
+			edit: func(e *Event) { e.Summary = "Daily standup" },
+			want: []string{
+				"SUMMARY:Daily standup",
+				"SENT-BY=",
+				"ROLE=CHAIR",
+				"CUTYPE=INDIVIDUAL",
+				"RSVP=TRUE",
+				"PARTSTAT=ACCEPTED",
+				"X-VENDOR-FLAG:kept",
+				"BEGIN:VALARM",
+			},
+		},
+		{
+			name: "move to all day",
+			edit: func(e *Event) {
+				e.AllDay = true
+				e.Start = time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC)
+				e.End = e.Start.AddDate(0, 0, 1)
+			},
+			want: []string{"DTSTART;VALUE=DATE:20251210", "DTEND;VALUE=DATE:20251211", "RSVP=TRUE"},
+		},
+		{
+			name:    "stale etag",
+			edit:    func(e *Event) { e.Summary = "Lost update" },
+			stale:   true,
+			wantErr: ErrPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeCalDAV{
+				resources: map[string]string{"/cal/standup.ics": strings.ReplaceAll(caldavFixture, "\n", "\r\n")},
+				etags:     map[string]int{"/cal/standup.ics": 1},
+			}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			c := NewCalDAVProvider(ts.URL+"/cal/", "", "", "cy@example.com")
+			ctx := context.Background()
+			e, err := c.GetEvent(ctx, "/cal/standup.ics")
+			if err != nil {
+				t.Fatalf("GetEvent: %v", err)
+			}
+			if tt.stale {
+				server.etags["/cal/standup.ics"]++
+			}
+			tt.edit(e)
+
+			saved, err := c.UpdateEvent(ctx, e)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateEvent error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateEvent: %v", err)
+			}
+			stored := strings.ReplaceAll(server.resources["/cal/standup.ics"], "\r\n ", "")
+			for _, want := range tt.want {
+				if !strings.Contains(stored, want) {
+					t.Errorf("stored event lacks %q:\n%s", want, stored)
+				}
+			}
+			if len(saved.Attendees) != 2 || saved.Attendees[0].ResponseStatus != "accepted" {
+				t.Errorf("saved attendees = %+v", saved.Attendees)
+			}
+		})
+	}
+}
@@ -3,23 +3,31 @@ package calendar
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"google.golang.org/api/calendar/v3"
+	"groundhog/internal/changes"
 )
 
-// EditEvent updates an existing event in the user's Google Calendar.
+// maxEditAttempts bounds how often an edit is re-applied after the event
+// changed underneath it.
+const maxEditAttempts = 3
+
+// EditEvent updates an existing event in the user's calendar.
 type EditEvent struct {
-	credFile string
+	provider CalendarProvider
+	travel   *TravelPolicy
 }
 
 var _ = EditEvent{}
 
-func NewEditEvent(credFile string) *EditEvent {
+// NewEditEvent returns the tool. travel may be nil to skip travel handling.
+func NewEditEvent(provider CalendarProvider, travel *TravelPolicy) *EditEvent {
 	return &EditEvent{
-		credFile: credFile,
+		provider: provider,
+		travel:   travel,
 	}
 }
 
@@ -28,7 +36,7 @@ func (e *EditEvent) Name() string {
 }
 
 func (e *EditEvent) Description() string {
-	return `Edit an existing Google Calendar event.
+	return `Edit an existing calendar event.
 
 Input must be a stringified JSON object like:
 {
@@ -46,11 +54,12 @@ Fields:
 - event_id (string, required): id returned by a calendar listing or search.
 - summary (string, optional)
 - description (string, optional)
-- start_time (string, optional): RFC3339 timestamp or YYYY-MM-DD for all-day events.
+- start_time (string, optional): RFC3339 timestamp or YYYY-MM-DD for all-day events. Relative expressions like "tomorrow 3pm" are also accepted.
 - end_time (string, optional): RFC3339 timestamp; omit when using duration_minutes.
 - duration_minutes (integer, optional): length in minutes when end_time is omitted.
 - time_zone (string, optional): IANA name applied to start/end when provided.
-- location (string, optional)`
+- location (string, optional)
+- ignore_availability (boolean, optional): move the event outside the user's availability; only when the user explicitly asks.`
 }
 
 // Parameters exposes the structured schema for tool calling.
@@ -72,7 +81,7 @@ func (e *EditEvent) Parameters() map[string]interface{} {
 			},
 			"start_time": map[string]interface{}{
 				"type":        "string",
-				"description": "RFC3339 timestamp or YYYY-MM-DD for all-day events.",
+				"description": "RFC3339 timestamp, YYYY-MM-DD for all-day events, or a relative expression like \"tomorrow 3pm\".",
 			},
 			"end_time": map[string]interface{}{
 				"type":        "string",
@@ -90,6 +99,10 @@ func (e *EditEvent) Parameters() map[string]interface{} {
 				"type":        "string",
 				"description": "Updated location (room, link, etc.).",
 			},
+			"ignore_availability": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Allow times outside the user's availability; only when the user explicitly asks.",
+			},
 		},
 		"required": []string{"event_id"},
 	}
@@ -106,23 +119,60 @@ func (e *EditEvent) Call(ctx context.Context, input string) (string, error) {
 		return "", err
 	}
 
-	srv, err := newCalendarService(ctx, e.credFile)
+	existing, err := e.provider.GetEvent(ctx, payload.EventID)
 	if err != nil {
 		return "", err
 	}
 
-	existing, err := srv.Events.Get("primary", payload.EventID).Context(ctx).Do()
-	if err != nil {
-		return "", fmt.Errorf("unable to fetch event %q: %w", payload.EventID, err)
+	// The write is conditional on the ETag read above. When the event changes
+	// in between, the edit is re-applied on top of the new version unless it
+	// touches the same fields.
+	base := existing
+	var saved *Event
+	for attempt := 1; ; attempt++ {
+		updated, conflict, err := applyEdit(ctx, base, payload)
+		if err != nil {
+			return "", err
+		}
+		if conflict != "" {
+			return conflict, nil
+		}
+
+		saved, err = e.provider.UpdateEvent(ctx, &updated)
+		if errors.Is(err, ErrPreconditionFailed) && attempt < maxEditAttempts {
+			if base, err = e.provider.GetEvent(ctx, payload.EventID); err != nil {
+				return "", err
+			}
+			if fields := editConflicts(ctx, existing, base, payload); len(fields) > 0 {
+				return fmt.Sprintf("Not updated: \"%s\" was changed elsewhere in the meantime (%s). Tell the user what changed and ask which version to keep before editing again.",
+					existing.Summary, strings.Join(fields, "; ")), nil
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		break
 	}
+	recordUpdate(ctx, base, saved)
+
+	startDisplay := stringifyEventTime(localEventTime(ctx, saved.Start, saved.AllDay), saved.AllDay, payload.StartTime)
+	endDisplay := stringifyEventTime(localEventTime(ctx, saved.End, saved.AllDay), saved.AllDay, payload.EndTime)
 
-	updated := &calendar.Event{
-		Summary:     existing.Summary,
-		Description: existing.Description,
-		Location:    existing.Location,
-		Start:       existing.Start,
-		End:         existing.End,
+	res := fmt.Sprintf("Updated calendar event \"%s\" (%s → %s).", saved.Summary, startDisplay, endDisplay)
+	if saved.HTMLLink != "" {
+		res = fmt.Sprintf("Updated calendar event \"%s\" (%s → %s). Link: %s", saved.Summary, startDisplay, endDisplay, saved.HTMLLink)
 	}
+	for _, line := range e.travel.travelNotes(ctx, e.provider, *saved) {
+		res += "\n" + line
+	}
+	return res, nil
+}
+
+// applyEdit returns base with the edit applied, or refusal text when the new
+// times break the user's availability.
+func applyEdit(ctx context.Context, base *Event, payload editEventInput) (Event, string, error) {
+	updated := *base
 
 	if payload.Summary != nil {
 		updated.Summary = strings.TrimSpace(*payload.Summary)
@@ -134,43 +184,104 @@ func (e *EditEvent) Call(ctx context.Context, input string) (string, error) {
 		updated.Location = strings.TrimSpace(*payload.Location)
 	}
 
-	timesChanged := payload.StartTime != nil || payload.EndTime != nil || payload.DurationMinutes != nil || payload.TimeZone != nil
-	if timesChanged {
-		start, end, allDay, tz, err := computeEditedTimes(existing, payload)
+	if payload.timesChanged() {
+		start, end, allDay, tz, err := computeEditedTimes(base, payload, userTimeZone(ctx))
 		if err != nil {
-			return "", err
+			return Event{}, "", err
 		}
-		if allDay {
-			updated.Start = &calendar.EventDateTime{
-				Date: start.Format(time.DateOnly),
-			}
-			updated.End = &calendar.EventDateTime{
-				Date: end.Format(time.DateOnly),
-			}
-		} else {
-			updated.Start = &calendar.EventDateTime{
-				DateTime: start.Format(time.RFC3339),
-				TimeZone: tz,
-			}
-			updated.End = &calendar.EventDateTime{
-				DateTime: end.Format(time.RFC3339),
-				TimeZone: tz,
+		updated.Start, updated.End, updated.AllDay, updated.TimeZone = start, end, allDay, tz
+		if !allDay && !payload.IgnoreAvailability {
+			if conflict := availabilityConflict(ctx, updated.Summary, start, end, isMeeting(*base)); conflict != "" {
+				return Event{}, conflict, nil
 			}
 		}
 	}
+	return updated, "", nil
+}
+
+// editConflicts lists the fields the edit touches that were also changed
+// between read and latest, with their new values.
+func editConflicts(ctx context.Context, read, latest *Event, payload editEventInput) []string {
+	var res []string
+	if payload.Summary != nil && latest.Summary != read.Summary {
+		res = append(res, fmt.Sprintf("title is now %q", latest.Summary))
+	}
+	if payload.Description != nil && latest.Description != read.Description {
+		res = append(res, "description was changed")
+	}
+	if payload.Location != nil && latest.Location != read.Location {
+		res = append(res, fmt.Sprintf("location is now %q", latest.Location))
+	}
+	if payload.timesChanged() && (!latest.Start.Equal(read.Start) || !latest.End.Equal(read.End) || latest.AllDay != read.AllDay) {
+		res = append(res, fmt.Sprintf("time is now %s → %s",
+			formatEventTime(localEventTime(ctx, latest.Start, latest.AllDay), latest.AllDay),
+			formatEventTime(localEventTime(ctx, latest.End, latest.AllDay), latest.AllDay)))
+	}
+	return res
+}
 
-	saved, err := srv.Events.Update("primary", payload.EventID, updated).Context(ctx).Do()
+// Describe validates input against the current event and lists the fields
+// that would change.
+func (e *EditEvent) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseEditEventInput(input)
 	if err != nil {
-		return "", fmt.Errorf("unable to update event: %w", err)
+		return "", err
+	}
+	existing, err := e.provider.GetEvent(ctx, payload.EventID)
+	if err != nil {
+		return "", err
 	}
 
-	startDisplay := stringifyEventTime(saved.Start, payload.StartTime)
-	endDisplay := stringifyEventTime(saved.End, payload.EndTime)
+	var diff []string
+	if payload.Summary != nil {
+		diff = append(diff, fmt.Sprintf("title → %q", strings.TrimSpace(*payload.Summary)))
+	}
+	if payload.Description != nil {
+		diff = append(diff, "description")
+	}
+	if payload.Location != nil {
+		diff = append(diff, fmt.Sprintf("location → %q", strings.TrimSpace(*payload.Location)))
+	}
+	if payload.timesChanged() {
+		start, end, allDay, _, err := computeEditedTimes(existing, payload, userTimeZone(ctx))
+		if err != nil {
+			return "", err
+		}
+		if !allDay && !payload.IgnoreAvailability {
+			if conflict := availabilityConflict(ctx, existing.Summary, start, end, isMeeting(*existing)); conflict != "" {
+				return "", changes.Refuse(conflict)
+			}
+		}
+		diff = append(diff, fmt.Sprintf("time %s → %s  ⇒  %s → %s",
+			formatEventTime(localEventTime(ctx, existing.Start, existing.AllDay), existing.AllDay),
+			formatEventTime(localEventTime(ctx, existing.End, existing.AllDay), existing.AllDay),
+			formatEventTime(localEventTime(ctx, start, allDay), allDay),
+			formatEventTime(localEventTime(ctx, end, allDay), allDay)))
+	}
+	return fmt.Sprintf("Edit event \"%s\": %s", existing.Summary, strings.Join(diff, "; ")), nil
+}
 
-	if saved.HtmlLink != "" {
-		return fmt.Sprintf("Updated calendar event \"%s\" (%s → %s). Link: %s", saved.Summary, startDisplay, endDisplay, saved.HtmlLink), nil
+// Resolve makes relative start and end times absolute.
+func (e *EditEvent) Resolve(ctx context.Context, input string) (string, error) {
+	payload, err := parseEditEventInput(input)
+	if err != nil {
+		return "", err
+	}
+	tz := userTimeZone(ensureContext(ctx))
+	if payload.TimeZone != nil && strings.TrimSpace(*payload.TimeZone) != "" {
+		tz = *payload.TimeZone
+	}
+	for _, field := range []*string{payload.StartTime, payload.EndTime} {
+		if field == nil {
+			continue
+		}
+		if *field, err = resolveTime(*field, tz); err != nil {
+			return "", err
+		}
 	}
-	return fmt.Sprintf("Updated calendar event \"%s\" (%s → %s).", saved.Summary, startDisplay, endDisplay), nil
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
 }
 
 type editEventInput struct {
@@ -182,6 +293,12 @@ type editEventInput struct {
 	DurationMinutes *int    `json:"duration_minutes,omitempty"`
 	TimeZone        *string `json:"time_zone,omitempty"`
 	Location        *string `json:"location,omitempty"`
+	// IgnoreAvailability allows moving the event outside the user's availability.
+	IgnoreAvailability bool `json:"ignore_availability,omitempty"`
+}
+
+func (in editEventInput) timesChanged() bool {
+	return in.StartTime != nil || in.EndTime != nil || in.DurationMinutes != nil || in.TimeZone != nil
 }
 
 func parseEditEventInput(raw string) (editEventInput, error) {
@@ -224,9 +341,11 @@ func validateEditEventInput(payload editEventInput) (editEventInput, error) {
 	return payload, nil
 }
 
-func computeEditedTimes(existing *calendar.Event, in editEventInput) (time.Time, time.Time, bool, string, error) {
-	existingStart := eventTimeString(existing.Start)
-	existingEnd := eventTimeString(existing.End)
+// computeEditedTimes merges the edit with the existing times. defaultTZ is used
+// when neither the input nor the event names a time zone.
+func computeEditedTimes(existing *Event, in editEventInput, defaultTZ string) (time.Time, time.Time, bool, string, error) {
+	existingStart := formatEventTime(existing.Start, existing.AllDay)
+	existingEnd := formatEventTime(existing.End, existing.AllDay)
 
 	startInput := pickString(in.StartTime, existingStart)
 	endInput := pickString(in.EndTime, existingEnd)
@@ -234,7 +353,10 @@ func computeEditedTimes(existing *calendar.Event, in editEventInput) (time.Time,
 		return time.Time{}, time.Time{}, false, "", fmt.Errorf("existing event has no start time; please provide start_time")
 	}
 
-	tz := pickString(in.TimeZone, existingTimezone(existing))
+	tz := pickString(in.TimeZone, existing.TimeZone)
+	if tz == "" {
+		tz = defaultTZ
+	}
 
 	start, startAllDay, err := parseTime(startInput, tz)
 	if err != nil {
@@ -276,29 +398,6 @@ func computeEditedTimes(existing *calendar.Event, in editEventInput) (time.Time,
 	return start, end, false, tz, nil
 }
 
-func eventTimeString(t *calendar.EventDateTime) string {
-	if t == nil {
-		return ""
-	}
-	if strings.TrimSpace(t.DateTime) != "" {
-		return t.DateTime
-	}
-	return strings.TrimSpace(t.Date)
-}
-
-func existingTimezone(e *calendar.Event) string {
-	if e == nil {
-		return ""
-	}
-	if e.Start != nil && strings.TrimSpace(e.Start.TimeZone) != "" {
-		return strings.TrimSpace(e.Start.TimeZone)
-	}
-	if e.End != nil && strings.TrimSpace(e.End.TimeZone) != "" {
-		return strings.TrimSpace(e.End.TimeZone)
-	}
-	return ""
-}
-
 func pickString(value *string, fallback string) string {
 	if value != nil {
 		return strings.TrimSpace(*value)
@@ -306,18 +405,9 @@ func pickString(value *string, fallback string) string {
 	return strings.TrimSpace(fallback)
 }
 
-func stringifyEventTime(t *calendar.EventDateTime, provided *string) string {
-	if t == nil {
-		if provided != nil {
-			return strings.TrimSpace(*provided)
-		}
-		return ""
-	}
-	if t.DateTime != "" {
-		return t.DateTime
-	}
-	if t.Date != "" {
-		return t.Date
+func stringifyEventTime(t time.Time, allDay bool, provided *string) string {
+	if display := formatEventTime(t, allDay); display != "" {
+		return display
 	}
 	if provided != nil {
 		return strings.TrimSpace(*provided)
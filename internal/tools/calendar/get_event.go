@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// GetEvent returns everything we know about one event so the agent can
+// prepare for it.
+type GetEvent struct {
+	provider CalendarProvider
+}
+
+var _ tools.Tool = &GetEvent{}
+
+func NewGetEvent(provider CalendarProvider) *GetEvent {
+	return &GetEvent{
+		provider: provider,
+	}
+}
+
+func (g *GetEvent) Name() string {
+	return "calendar_get_event"
+}
+
+func (g *GetEvent) Description() string {
+	return `Get the full details of one calendar event: times, location, description, conference link, organizer and attendees with their response status (accepted, declined, tentative, needsAction).
+
+Input is a stringified JSON object like {"event_id": "abc123"}; take the id from the calendar listing.`
+}
+
+func (g *GetEvent) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"event_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the event (required).",
+			},
+		},
+		"required": []string{"event_id"},
+	}
+}
+
+func (g *GetEvent) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	var payload struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(input)), &payload); err != nil {
+		return "", fmt.Errorf("invalid get event payload; expected a JSON object: %w", err)
+	}
+	if strings.TrimSpace(payload.EventID) == "" {
+		return "", fmt.Errorf("event_id is required")
+	}
+
+	e, err := g.provider.GetEvent(ctx, strings.TrimSpace(payload.EventID))
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := json.Marshal(newEventDocument(ctx, e))
+	if err != nil {
+		return "", err
+	}
+	return string(doc), nil
+}
+
+// eventDocument is the compact form of an event shown to the agent. Empty
+// fields are left out to save tokens.
+type eventDocument struct {
+	ID            string             `json:"id"`
+	Summary       string             `json:"summary"`
+	Start         string             `json:"start"`
+	End           string             `json:"end"`
+	AllDay        bool               `json:"all_day,omitempty"`
+	TimeZone      string             `json:"time_zone,omitempty"`
+	Status        string             `json:"status,omitempty"`
+	Location      string             `json:"location,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	ConferenceURL string             `json:"conference_url,omitempty"`
+	Link          string             `json:"link,omitempty"`
+	Recurring     bool               `json:"recurring,omitempty"`
+	Immovable     bool               `json:"immovable,omitempty"`
+	Organizer     *attendeeDocument  `json:"organizer,omitempty"`
+	Attendees     []attendeeDocument `json:"attendees,omitempty"`
+}
+
+type attendeeDocument struct {
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	Response string `json:"response,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Self     bool   `json:"self,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+func newEventDocument(ctx context.Context, e *Event) eventDocument {
+	doc := eventDocument{
+		ID:            e.ID,
+		Summary:       e.Summary,
+		Start:         formatEventTime(localEventTime(ctx, e.Start, e.AllDay), e.AllDay),
+		End:           formatEventTime(localEventTime(ctx, e.End, e.AllDay), e.AllDay),
+		AllDay:        e.AllDay,
+		TimeZone:      e.TimeZone,
+		Status:        e.Status,
+		Location:      e.Location,
+		Description:   e.Description,
+		ConferenceURL: e.ConferenceURL,
+		Link:          e.HTMLLink,
+		Recurring:     e.Recurring,
+		Immovable:     e.Immovable,
+	}
+	if e.AllDay {
+		// End is exclusive; show the last day the event covers.
+		doc.End = e.End.AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+	if e.Organizer != nil {
+		doc.Organizer = &attendeeDocument{Email: e.Organizer.Email, Name: e.Organizer.Name, Self: e.Organizer.Self}
+	}
+	for _, a := range e.Attendees {
+		doc.Attendees = append(doc.Attendees, attendeeDocument{
+			Email:    a.Email,
+			Name:     a.Name,
+			Response: a.ResponseStatus,
+			Optional: a.Optional,
+			Self:     a.Self,
+			Comment:  a.Comment,
+		})
+	}
+	return doc
+}
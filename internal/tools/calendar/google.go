@@ -0,0 +1,388 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const googleCalendarID = "primary"
+
+// GoogleProvider stores events in the user's primary Google Calendar. The
+// credentials come from the request context (OAuth) or a service account file.
+type GoogleProvider struct {
+	credFile string
+}
+
+var _ CalendarProvider = &GoogleProvider{}
+
+func NewGoogleProvider(credFile string) *GoogleProvider {
+	return &GoogleProvider{
+		credFile: credFile,
+	}
+}
+
+func (g *GoogleProvider) ListEvents(ctx context.Context, from, to time.Time) ([]Event, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Event
+	pageToken := ""
+	for {
+		call := srv.Events.List(googleCalendarID).
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(from.Format(time.RFC3339)).
+			TimeMax(to.Format(time.RFC3339)).
+			OrderBy("startTime").
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		events, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve events: %w", err)
+		}
+		for _, item := range events.Items {
+			e, err := fromGoogleEvent(item)
+			if err != nil {
+				continue
+			}
+			res = append(res, e)
+		}
+		if events.NextPageToken == "" {
+			return res, nil
+		}
+		pageToken = events.NextPageToken
+	}
+}
+
+// SyncEvents implements incremental sync with Google's sync tokens. The
+// initial full sync starts at since; later calls return only what changed.
+func (g *GoogleProvider) SyncEvents(ctx context.Context, token string, since, until time.Time) ([]Event, []string, string, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var changed []Event
+	var removed []string
+	pageToken := ""
+	for {
+		call := srv.Events.List(googleCalendarID).
+			SingleEvents(true).
+			MaxResults(2500).
+			Context(ctx)
+		// A sync token carries the window of the full sync it came from;
+		// Google rejects time bounds next to it.
+		if token != "" {
+			call = call.SyncToken(token)
+		} else {
+			call = call.TimeMin(since.Format(time.RFC3339)).TimeMax(until.Format(time.RFC3339))
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		events, err := call.Do()
+		if err != nil {
+			var apiErr *googleapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusGone {
+				return nil, nil, "", ErrSyncTokenExpired
+			}
+			return nil, nil, "", fmt.Errorf("unable to sync events: %w", err)
+		}
+		for _, item := range events.Items {
+			if item.Status == "cancelled" {
+				removed = append(removed, item.Id)
+				continue
+			}
+			e, err := fromGoogleEvent(item)
+			if err != nil {
+				removed = append(removed, item.Id)
+				continue
+			}
+			changed = append(changed, e)
+		}
+		if events.NextPageToken == "" {
+			return changed, removed, events.NextSyncToken, nil
+		}
+		pageToken = events.NextPageToken
+	}
+}
+
+// HasUID reports whether an event with the iCalendar UID exists, at any
+// date.
+func (g *GoogleProvider) HasUID(ctx context.Context, uid string) (bool, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return false, err
+	}
+	events, err := srv.Events.List(googleCalendarID).ICalUID(uid).MaxResults(1).Context(ctx).Do()
+	if err != nil {
+		return false, fmt.Errorf("unable to look up event %q: %w", uid, err)
+	}
+	return len(events.Items) > 0, nil
+}
+
+func (g *GoogleProvider) GetEvent(ctx context.Context, id string) (*Event, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := srv.Events.Get(googleCalendarID, id).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch event %q: %w", id, err)
+	}
+	e, err := fromGoogleEvent(item)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (g *GoogleProvider) InsertEvent(ctx context.Context, event *Event) (*Event, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return nil, err
+	}
+
+	item := toGoogleEvent(event)
+	item.ICalUID = event.UID
+	created, err := srv.Events.Insert(googleCalendarID, item).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create event: %w", err)
+	}
+	e, err := fromGoogleEvent(created)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (g *GoogleProvider) UpdateEvent(ctx context.Context, event *Event) (*Event, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return nil, err
+	}
+
+	call := srv.Events.Patch(googleCalendarID, event.ID, toGoogleEvent(event)).Context(ctx)
+	if event.ETag != "" {
+		call.Header().Set("If-Match", event.ETag)
+	}
+	saved, err := call.Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return nil, fmt.Errorf("unable to update event: %w", ErrPreconditionFailed)
+		}
+		return nil, fmt.Errorf("unable to update event: %w", err)
+	}
+	e, err := fromGoogleEvent(saved)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (g *GoogleProvider) DeleteEvent(ctx context.Context, id string) error {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return err
+	}
+
+	if err := srv.Events.Delete(googleCalendarID, id).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to delete event %q: %w", id, err)
+	}
+	return nil
+}
+
+func (g *GoogleProvider) RespondToEvent(ctx context.Context, id, response, comment string) (*Event, error) {
+	srv, err := newCalendarService(ctx, g.credFile)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := srv.Events.Get(googleCalendarID, id).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch event %q: %w", id, err)
+	}
+	// Attendees are replaced as a whole by a patch, so send the full list
+	// with only our own entry changed.
+	found := false
+	for _, a := range item.Attendees {
+		if a != nil && a.Self {
+			a.ResponseStatus = response
+			a.Comment = comment
+			a.ForceSendFields = append(a.ForceSendFields, "Comment")
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unable to respond to event %q: you are not an attendee", id)
+	}
+
+	patch := &calendar.Event{Attendees: item.Attendees}
+	saved, err := srv.Events.Patch(googleCalendarID, id, patch).SendUpdates("all").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to respond to event %q: %w", id, err)
+	}
+	e, err := fromGoogleEvent(saved)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// toGoogleEvent converts the fields modelled by Event. Text fields are always
+// sent so that clearing them through a patch works.
+func toGoogleEvent(e *Event) *calendar.Event {
+	item := &calendar.Event{
+		Summary:         e.Summary,
+		Description:     e.Description,
+		Location:        e.Location,
+		ForceSendFields: []string{"Summary", "Description", "Location"},
+	}
+	// A patch merges start and end into the stored ones, so the other kind
+	// of time is cleared for events switching between all-day and timed.
+	if e.AllDay {
+		item.Start = &calendar.EventDateTime{Date: e.Start.Format(time.DateOnly), NullFields: []string{"DateTime", "TimeZone"}}
+		item.End = &calendar.EventDateTime{Date: e.End.Format(time.DateOnly), NullFields: []string{"DateTime", "TimeZone"}}
+	} else {
+		item.Start = &calendar.EventDateTime{DateTime: e.Start.Format(time.RFC3339), TimeZone: e.TimeZone, NullFields: []string{"Date"}}
+		item.End = &calendar.EventDateTime{DateTime: e.End.Format(time.RFC3339), TimeZone: e.TimeZone, NullFields: []string{"Date"}}
+	}
+	if e.Immovable {
+		item.ExtendedProperties = &calendar.EventExtendedProperties{
+			Private: map[string]string{immovableProperty: "true"},
+		}
+	}
+	return item
+}
+
+func fromGoogleEvent(item *calendar.Event) (Event, error) {
+	if item == nil {
+		return Event{}, fmt.Errorf("empty event")
+	}
+
+	e := Event{
+		ID:          item.Id,
+		UID:         item.ICalUID,
+		Calendar:    googleCalendarID,
+		Summary:     item.Summary,
+		Description: item.Description,
+		Location:    item.Location,
+		Status:      item.Status,
+		HTMLLink:    item.HtmlLink,
+		TimeZone:    existingTimezone(item),
+		Locked:      item.Locked,
+		ETag:        item.Etag,
+	}
+	if item.Organizer != nil {
+		e.Organizer = &Attendee{
+			Email: item.Organizer.Email,
+			Name:  item.Organizer.DisplayName,
+			Self:  item.Organizer.Self,
+		}
+	}
+	for _, a := range item.Attendees {
+		if a == nil {
+			continue
+		}
+		e.Attendees = append(e.Attendees, Attendee{
+			Email:          a.Email,
+			Name:           a.DisplayName,
+			ResponseStatus: a.ResponseStatus,
+			Self:           a.Self,
+			Optional:       a.Optional,
+			Comment:        a.Comment,
+		})
+	}
+	e.Recurring = item.RecurringEventId != ""
+	e.ConferenceURL = item.HangoutLink
+	if item.ConferenceData != nil {
+		for _, ep := range item.ConferenceData.EntryPoints {
+			if ep != nil && ep.EntryPointType == "video" && ep.Uri != "" {
+				e.ConferenceURL = ep.Uri
+				break
+			}
+		}
+	}
+	if item.ExtendedProperties != nil && item.ExtendedProperties.Private[immovableProperty] == "true" {
+		e.Immovable = true
+	}
+
+	var err error
+	if e.Start, e.AllDay, err = parseGoogleTime(item.Start); err != nil {
+		return Event{}, fmt.Errorf("event %q: invalid start: %w", item.Id, err)
+	}
+	if e.End, _, err = parseGoogleTime(item.End); err != nil {
+		return Event{}, fmt.Errorf("event %q: invalid end: %w", item.Id, err)
+	}
+	return e, nil
+}
+
+func parseGoogleTime(t *calendar.EventDateTime) (time.Time, bool, error) {
+	if t == nil {
+		return time.Time{}, false, fmt.Errorf("time is missing")
+	}
+	if strings.TrimSpace(t.DateTime) != "" {
+		parsed, err := time.Parse(time.RFC3339, t.DateTime)
+		return parsed, false, err
+	}
+	parsed, err := time.Parse(time.DateOnly, strings.TrimSpace(t.Date))
+	return parsed, true, err
+}
+
+func existingTimezone(e *calendar.Event) string {
+	if e == nil {
+		return ""
+	}
+	if e.Start != nil && strings.TrimSpace(e.Start.TimeZone) != "" {
+		return strings.TrimSpace(e.Start.TimeZone)
+	}
+	if e.End != nil && strings.TrimSpace(e.End.TimeZone) != "" {
+		return strings.TrimSpace(e.End.TimeZone)
+	}
+	return ""
+}
+
+func newCalendarService(ctx context.Context, credFile string) (*calendar.Service, error) {
+	cred, err := resolveCredential(ctx, credFile)
+	if err != nil {
+		return nil, err
+	}
+	return calendar.NewService(ctx, cred)
+}
+
+func resolveCredential(ctx context.Context, credFile string) (option.ClientOption, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tokenSource := ctx.Value("OauthTokenSource")
+	if tokenSource == nil && credFile == "" {
+		return nil, fmt.Errorf("authentication for calendar tool is not configured yet")
+	}
+
+	if tokenSource != nil {
+		ts, ok := tokenSource.(oauth2.TokenSource)
+		if !ok || ts == nil {
+			return nil, fmt.Errorf("context value OauthTokenSource is not valid")
+		}
+		return option.WithTokenSource(ts), nil
+	}
+
+	return option.WithCredentialsFile(credFile), nil
+}
@@ -0,0 +1,256 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"groundhog/internal/ical"
+	"groundhog/internal/profile"
+)
+
+// ImportResult summarizes an ICS import.
+type ImportResult struct {
+	Created  int                 `json:"created"`
+	Skipped  int                 `json:"skipped"`
+	Failed   int                 `json:"failed"`
+	Events   []ImportEventResult `json:"events,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+// ImportEventResult is what happened to one event of an ICS import.
+type ImportEventResult struct {
+	UID     string `json:"uid,omitempty"`
+	Summary string `json:"summary"`
+	// Status is created, skipped or failed.
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// uidFinder is implemented by providers that can look an event up by its
+// iCalendar UID regardless of its date.
+type uidFinder interface {
+	HasUID(ctx context.Context, uid string) (bool, error)
+}
+
+// ExportICS writes the events overlapping [from, to) as an iCalendar document
+// and returns how many events were written.
+func ExportICS(ctx context.Context, provider CalendarProvider, from, to time.Time, w io.Writer) (int, error) {
+	events, err := provider.ListEvents(ensureContext(ctx), from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	components := make([]*ical.Component, 0, len(events))
+	for _, e := range events {
+		uid := e.UID
+		if uid == "" {
+			uid = e.ID + "@groundhog"
+		}
+		out := ical.Event{
+			UID:         uid,
+			Summary:     e.Summary,
+			Description: e.Description,
+			Location:    e.Location,
+			Status:      e.Status,
+			Start:       e.Start,
+			End:         e.End,
+			AllDay:      e.AllDay,
+		}
+		if e.Organizer != nil {
+			out.Organizer = &ical.Attendee{Email: e.Organizer.Email, Name: e.Organizer.Name}
+		}
+		for _, a := range e.Attendees {
+			out.Attendees = append(out.Attendees, ical.Attendee{Email: a.Email, Name: a.Name, PartStat: toPartStat(a.ResponseStatus)})
+		}
+		vevent := ical.NewEvent(out)
+		if e.HTMLLink != "" {
+			vevent.Set(ical.Property{Name: "URL", Value: e.HTMLLink})
+		}
+		components = append(components, vevent)
+	}
+
+	if err := ical.Encode(w, ical.NewCalendar(components...)); err != nil {
+		return 0, fmt.Errorf("unable to write calendar: %w", err)
+	}
+	return len(events), nil
+}
+
+// ImportICS creates the VEVENTs of an iCalendar document. Events whose UID
+// already exists in the calendar, or appears twice in the file, are skipped.
+// An error means nothing was imported; events that fail on their own are
+// counted in Failed.
+func ImportICS(ctx context.Context, provider CalendarProvider, r io.Reader) (ImportResult, error) {
+	ctx = ensureContext(ctx)
+
+	roots, err := ical.Decode(r)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("invalid iCalendar data: %w", err)
+	}
+
+	var result ImportResult
+	var pending []Event
+	var rangeStart, rangeEnd time.Time
+	for _, root := range roots {
+		for _, vevent := range root.Children("VEVENT") {
+			e, warning, err := eventFromVEvent(vevent, profile.Location(ctx))
+			if err != nil {
+				result.Skipped++
+				result.Warnings = append(result.Warnings, err.Error())
+				continue
+			}
+			if warning != "" {
+				result.Warnings = append(result.Warnings, warning)
+			}
+			if rangeStart.IsZero() || e.Start.Before(rangeStart) {
+				rangeStart = e.Start
+			}
+			if e.End.After(rangeEnd) {
+				rangeEnd = e.End
+			}
+			pending = append(pending, e)
+		}
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	existing, err := provider.ListEvents(ctx, rangeStart, rangeEnd)
+	if err != nil {
+		return result, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		if e.UID != "" {
+			seen[e.UID] = true
+		}
+	}
+	// An event imported before may have been moved out of the file's range
+	// since, so UIDs are also looked up at any date where the provider can.
+	finder, _ := unwrapCache(provider).(uidFinder)
+
+	// A failing event doesn't stop the import; each one is reported.
+	for _, e := range pending {
+		res := ImportEventResult{UID: e.UID, Summary: e.Summary}
+		if e.UID != "" && !seen[e.UID] && finder != nil {
+			found, err := finder.HasUID(ctx, e.UID)
+			if err != nil {
+				res.Status, res.Error = "failed", err.Error()
+				result.Failed++
+				result.Events = append(result.Events, res)
+				continue
+			}
+			seen[e.UID] = found
+		}
+		if e.UID != "" && seen[e.UID] {
+			res.Status = "skipped"
+			result.Skipped++
+			result.Events = append(result.Events, res)
+			continue
+		}
+		created, err := provider.InsertEvent(ctx, &e)
+		if err != nil {
+			res.Status, res.Error = "failed", err.Error()
+			result.Failed++
+			result.Events = append(result.Events, res)
+			continue
+		}
+		recordCreate(ctx, created)
+		if e.UID != "" {
+			seen[e.UID] = true
+		}
+		res.Status, res.ID = "created", created.ID
+		result.Created++
+		result.Events = append(result.Events, res)
+	}
+	return result, nil
+}
+
+// unwrapCache returns the provider behind a CachedProvider.
+func unwrapCache(provider CalendarProvider) CalendarProvider {
+	if c, ok := provider.(*CachedProvider); ok {
+		return c.CalendarProvider
+	}
+	return provider
+}
+
+// eventFromVEvent runs the VEVENT times through the same validation the
+// add-event tool uses, so imported events follow the same all-day and
+// default-duration rules. Floating times are read in loc.
+func eventFromVEvent(vevent *ical.Component, loc *time.Location) (Event, string, error) {
+	uid := vevent.Value("UID")
+	summary := ical.UnescapeText(vevent.Value("SUMMARY"))
+	if summary == "" {
+		summary = "(no title)"
+	}
+	if vevent.Value("RECURRENCE-ID") != "" {
+		return Event{}, "", fmt.Errorf("%q: modified occurrences of recurring events are not imported", summary)
+	}
+
+	startProp, ok := vevent.Get("DTSTART")
+	if !ok {
+		return Event{}, "", fmt.Errorf("%q: event has no DTSTART", summary)
+	}
+	in := addEventInput{
+		Summary:     summary,
+		Description: ical.UnescapeText(vevent.Value("DESCRIPTION")),
+		Location:    ical.UnescapeText(vevent.Value("LOCATION")),
+		TimeZone:    startProp.Param("TZID"),
+	}
+	if in.TimeZone != "" {
+		if _, err := time.LoadLocation(in.TimeZone); err != nil {
+			in.TimeZone = ""
+		}
+	}
+
+	start, allDay, err := ical.ParseDateTime(startProp, loc)
+	if err != nil {
+		return Event{}, "", fmt.Errorf("%q: invalid DTSTART: %w", summary, err)
+	}
+	in.StartTime = formatEventTime(start, allDay)
+
+	if endProp, ok := vevent.Get("DTEND"); ok {
+		end, endAllDay, err := ical.ParseDateTime(endProp, loc)
+		if err != nil {
+			return Event{}, "", fmt.Errorf("%q: invalid DTEND: %w", summary, err)
+		}
+		in.EndTime = formatEventTime(end, endAllDay)
+	} else if raw := vevent.Value("DURATION"); raw != "" {
+		d, err := ical.ParseDuration(raw)
+		if err != nil {
+			return Event{}, "", fmt.Errorf("%q: invalid DURATION: %w", summary, err)
+		}
+		if allDay {
+			in.EndTime = formatEventTime(start.Add(d), true)
+		} else {
+			in.DurationMinutes = int(d / time.Minute)
+		}
+	}
+
+	payload, err := validateAddEventInput(in)
+	if err != nil {
+		return Event{}, "", fmt.Errorf("%q: %w", summary, err)
+	}
+	start, end, allDay, tz, err := prepareEventTimes(payload)
+	if err != nil {
+		return Event{}, "", fmt.Errorf("%q: %w", summary, err)
+	}
+
+	var warning string
+	if vevent.Value("RRULE") != "" {
+		warning = fmt.Sprintf("%q: recurrence rule ignored, only the first occurrence was imported", summary)
+	}
+
+	return Event{
+		UID:         uid,
+		Summary:     payload.Summary,
+		Description: payload.Description,
+		Location:    payload.Location,
+		Start:       start,
+		End:         end,
+		AllDay:      allDay,
+		TimeZone:    tz,
+	}, warning, nil
+}
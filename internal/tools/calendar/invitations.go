@@ -0,0 +1,319 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"groundhog/internal/changes"
+	"groundhog/internal/profile"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+const defaultInvitationDays = 30
+
+// ListInvitations lists upcoming events the user hasn't answered yet.
+type ListInvitations struct {
+	provider CalendarProvider
+}
+
+var _ tools.Tool = &ListInvitations{}
+
+func NewListInvitations(provider CalendarProvider) *ListInvitations {
+	return &ListInvitations{
+		provider: provider,
+	}
+}
+
+func (l *ListInvitations) Name() string {
+	return "calendar_list_invitations"
+}
+
+func (l *ListInvitations) Description() string {
+	return `List pending calendar invitations (events the user hasn't accepted or declined yet), with the events and availability rules each one conflicts with.
+
+Input is an optional stringified JSON object like {"days_ahead": 30}.`
+}
+
+func (l *ListInvitations) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"days_ahead": map[string]interface{}{
+				"type":        "integer",
+				"description": "How many days ahead to look (default 30).",
+			},
+		},
+		"required": []string{},
+	}
+}
+
+func (l *ListInvitations) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	var payload struct {
+		DaysAhead int `json:"days_ahead"`
+	}
+	if trimmed := strings.TrimSpace(input); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+			return "", fmt.Errorf("invalid list invitations payload; expected a JSON object: %w", err)
+		}
+	}
+	if payload.DaysAhead < 0 {
+		return "", fmt.Errorf("days_ahead cannot be negative")
+	}
+	if payload.DaysAhead == 0 {
+		payload.DaysAhead = defaultInvitationDays
+	}
+
+	from := time.Now().In(profile.Location(ctx))
+	to := from.AddDate(0, 0, payload.DaysAhead)
+	events, err := l.provider.ListEvents(ctx, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, e := range events {
+		if e.Status == "cancelled" || selfResponse(&e) != "needsAction" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s → %s – %s (id: %s)",
+			formatEventTime(localEventTime(ctx, e.Start, e.AllDay), e.AllDay),
+			formatEventTime(localEventTime(ctx, e.End, e.AllDay), e.AllDay),
+			e.Summary, e.ID)
+		if e.Organizer != nil {
+			fmt.Fprintf(&b, " from %s", attendeeName(*e.Organizer))
+		}
+		b.WriteString("\n")
+		if conflicts := invitationConflicts(ctx, events, &e); len(conflicts) > 0 {
+			fmt.Fprintf(&b, "  conflicts: %s\n", strings.Join(conflicts, "; "))
+		}
+	}
+	if b.Len() == 0 {
+		return fmt.Sprintf("No pending invitations in the next %d days.", payload.DaysAhead), nil
+	}
+	return b.String(), nil
+}
+
+// RespondToInvitation accepts, declines or tentatively accepts an
+// invitation on the user's behalf.
+type RespondToInvitation struct {
+	provider CalendarProvider
+}
+
+var _ tools.Tool = &RespondToInvitation{}
+
+func NewRespondToInvitation(provider CalendarProvider) *RespondToInvitation {
+	return &RespondToInvitation{
+		provider: provider,
+	}
+}
+
+func (r *RespondToInvitation) Name() string {
+	return "calendar_respond_invitation"
+}
+
+func (r *RespondToInvitation) Description() string {
+	return `Answer a calendar invitation for the user. The organizer is notified.
+
+Input must be a stringified JSON object like:
+{"event_id": "abc123", "response": "accepted", "comment": "Will join 5 minutes late"}
+
+Fields:
+- event_id (string, required): id from calendar_list_invitations or a calendar listing.
+- response (string, required): accepted, declined or tentative.
+- comment (string, optional): note for the organizer.
+- ignore_conflicts (boolean, optional): accept even though the event overlaps other events or the user's availability; only when the user explicitly asks.`
+}
+
+func (r *RespondToInvitation) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"event_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the event to answer (required).",
+			},
+			"response": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"accepted", "declined", "tentative"},
+				"description": "The user's answer (required).",
+			},
+			"comment": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional note for the organizer.",
+			},
+			"ignore_conflicts": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Accept despite conflicts; only when the user explicitly asks.",
+			},
+		},
+		"required": []string{"event_id", "response"},
+	}
+}
+
+func (r *RespondToInvitation) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseRespondInput(input)
+	if err != nil {
+		return "", err
+	}
+	existing, conflict, err := r.check(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	if conflict != "" {
+		return conflict, nil
+	}
+
+	saved, err := r.provider.RespondToEvent(ctx, existing.ID, payload.Response, payload.Comment)
+	if err != nil {
+		return "", err
+	}
+	recordResponse(ctx, existing, saved)
+
+	return fmt.Sprintf("Answered \"%s\" (%s) with %s.", saved.Summary,
+		formatEventTime(localEventTime(ctx, saved.Start, saved.AllDay), saved.AllDay), payload.Response), nil
+}
+
+// Describe validates the answer, including the conflict check, without
+// sending it.
+func (r *RespondToInvitation) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseRespondInput(input)
+	if err != nil {
+		return "", err
+	}
+	existing, conflict, err := r.check(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	if conflict != "" {
+		return "", changes.Refuse(conflict)
+	}
+
+	summary := fmt.Sprintf("Answer \"%s\" (%s) with %s", existing.Summary,
+		formatEventTime(localEventTime(ctx, existing.Start, existing.AllDay), existing.AllDay), payload.Response)
+	if payload.Comment != "" {
+		summary += fmt.Sprintf(" and comment %q", payload.Comment)
+	}
+	return summary, nil
+}
+
+// check loads the invitation and, unless declining, returns refusal text when
+// it overlaps other events or breaks the user's availability.
+func (r *RespondToInvitation) check(ctx context.Context, in respondInput) (*Event, string, error) {
+	existing, err := r.provider.GetEvent(ctx, in.EventID)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, ok := selfAttendee(existing); !ok {
+		return nil, "", fmt.Errorf("the user is not invited to %q", existing.Summary)
+	}
+	if in.Response == "declined" || in.IgnoreConflicts || existing.AllDay {
+		return existing, "", nil
+	}
+
+	events, err := r.provider.ListEvents(ctx, existing.Start, existing.End)
+	if err != nil {
+		return nil, "", err
+	}
+	conflicts := invitationConflicts(ctx, events, existing)
+	if len(conflicts) == 0 {
+		return existing, "", nil
+	}
+	return existing, fmt.Sprintf("Not answered: \"%s\" conflicts with %s. Ask the user how to proceed; retry with ignore_conflicts set to true only if they still want to %s.",
+		existing.Summary, strings.Join(conflicts, "; "), strings.TrimSuffix(in.Response, "ed")), nil
+}
+
+type respondInput struct {
+	EventID         string `json:"event_id"`
+	Response        string `json:"response"`
+	Comment         string `json:"comment,omitempty"`
+	IgnoreConflicts bool   `json:"ignore_conflicts,omitempty"`
+}
+
+func parseRespondInput(raw string) (respondInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return respondInput{}, fmt.Errorf("provide the answer as a JSON object in the tool input")
+	}
+
+	var payload respondInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return respondInput{}, fmt.Errorf("invalid respond payload; expected a JSON object: %w", err)
+	}
+
+	payload.EventID = strings.TrimSpace(payload.EventID)
+	if payload.EventID == "" {
+		return respondInput{}, fmt.Errorf("event_id is required to answer an invitation")
+	}
+	payload.Comment = strings.TrimSpace(payload.Comment)
+	switch strings.ToLower(strings.TrimSpace(payload.Response)) {
+	case "accepted", "accept", "yes":
+		payload.Response = "accepted"
+	case "declined", "decline", "no":
+		payload.Response = "declined"
+	case "tentative", "maybe":
+		payload.Response = "tentative"
+	default:
+		return respondInput{}, fmt.Errorf("response must be accepted, declined or tentative")
+	}
+	return payload, nil
+}
+
+// invitationConflicts lists the timed events in events that overlap e and
+// the availability rules e breaks. Events the user declined don't count.
+func invitationConflicts(ctx context.Context, events []Event, e *Event) []string {
+	if e.AllDay {
+		return nil
+	}
+
+	var res []string
+	for _, o := range events {
+		if o.ID == e.ID || o.AllDay || o.Status == "cancelled" || selfResponse(&o) == "declined" {
+			continue
+		}
+		if o.Start.Before(e.End) && e.Start.Before(o.End) {
+			res = append(res, fmt.Sprintf("\"%s\" (%s–%s)", o.Summary,
+				localEventTime(ctx, o.Start, false).Format("15:04"),
+				localEventTime(ctx, o.End, false).Format("15:04")))
+		}
+	}
+	return append(res, availabilityViolations(ctx, e.Summary, e.Start, e.End, true)...)
+}
+
+// selfAttendee returns the user's own attendee entry.
+func selfAttendee(e *Event) (Attendee, bool) {
+	for _, a := range e.Attendees {
+		if a.Self {
+			return a, true
+		}
+	}
+	return Attendee{}, false
+}
+
+// selfResponse is the user's response status, or "" when they aren't an
+// attendee (e.g. their own events without guests).
+func selfResponse(e *Event) string {
+	a, _ := selfAttendee(e)
+	return a.ResponseStatus
+}
+
+func attendeeName(a Attendee) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Email
+}
@@ -5,9 +5,7 @@ import (
 	"fmt"
 	"time"
 
-	"golang.org/x/oauth2"
-	"google.golang.org/api/calendar/v3"
-	"google.golang.org/api/option"
+	"groundhog/internal/profile"
 
 	"github.com/tmc/langchaingo/callbacks"
 	"github.com/tmc/langchaingo/tools"
@@ -15,7 +13,7 @@ import (
 
 // Calendar lists upcoming events for the user.
 type Calendar struct {
-	credFile         string
+	provider         CalendarProvider
 	CallbacksHandler callbacks.Handler
 }
 
@@ -23,18 +21,23 @@ var (
 	_ tools.Tool = &Calendar{}
 )
 
-func NewListEvent(credFile string) *Calendar {
+func NewListEvent(provider CalendarProvider) *Calendar {
 	return &Calendar{
-		credFile: credFile,
+		provider: provider,
 	}
 }
 
+// Provider returns the backend the tool reads from.
+func (c *Calendar) Provider() CalendarProvider {
+	return c.provider
+}
+
 func (c *Calendar) Name() string {
 	return "calendar"
 }
 
 func (c *Calendar) Description() string {
-	return `List the user's upcoming Google Calendar events for the next 72 hours, including each event's id for follow-up edits.`
+	return `List the user's upcoming calendar events for the next 72 hours, including each event's id for follow-up edits.`
 }
 
 func (a *Calendar) Parameters() map[string]interface{} {
@@ -52,38 +55,21 @@ func (c *Calendar) Call(ctx context.Context, input string) (string, error) {
 		return "", err
 	}
 
-	srv, err := newCalendarService(ctx, c.credFile)
-	if err != nil {
-		return "", err
-	}
-
-	start := time.Now().Format(time.RFC3339)
-	end := time.Now().Add(3 * 24 * time.Hour).Format(time.RFC3339)
+	start := time.Now().In(profile.Location(ctx))
+	end := start.Add(3 * 24 * time.Hour)
 
-	eventsCall := srv.Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(start).
-		TimeMax(end).
-		OrderBy("startTime").
-		Context(ctx)
-
-	events, err := eventsCall.Do()
+	events, err := c.provider.ListEvents(ctx, start, end)
 	if err != nil {
-		return "", fmt.Errorf("unable to retrieve events: %w", err)
+		return "", err
 	}
 
-	if len(events.Items) == 0 {
+	if len(events) == 0 {
 		return "No upcoming events found.", nil
 	}
 
 	var result string
-	for _, e := range events.Items {
-		start := e.Start.DateTime
-		if start == "" {
-			start = e.Start.Date
-		}
-		result += fmt.Sprintf("%s – %s (id: %s)\n", start, e.Summary, e.Id)
+	for _, e := range events {
+		result += fmt.Sprintf("%s – %s (id: %s)\n", formatEventTime(localEventTime(ctx, e.Start, e.AllDay), e.AllDay), e.Summary, e.ID)
 	}
 	return result, nil
 }
@@ -94,32 +80,3 @@ func ensureContext(ctx context.Context) context.Context {
 	}
 	return ctx
 }
-
-func newCalendarService(ctx context.Context, credFile string) (*calendar.Service, error) {
-	cred, err := resolveCredential(ctx, credFile)
-	if err != nil {
-		return nil, err
-	}
-	return calendar.NewService(ctx, cred)
-}
-
-func resolveCredential(ctx context.Context, credFile string) (option.ClientOption, error) {
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	tokenSource := ctx.Value("OauthTokenSource")
-	if tokenSource == nil && credFile == "" {
-		return nil, fmt.Errorf("authentication for calendar tool is not configured yet")
-	}
-
-	if tokenSource != nil {
-		ts, ok := tokenSource.(oauth2.TokenSource)
-		if !ok || ts == nil {
-			return nil, fmt.Errorf("context value OauthTokenSource is not valid")
-		}
-		return option.WithTokenSource(ts), nil
-	}
-
-	return option.WithCredentialsFile(credFile), nil
-}
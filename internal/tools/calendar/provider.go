@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"groundhog/internal/profile"
+)
+
+// CalendarProvider is the storage backend the calendar tools talk to. Every
+// call receives the request context so providers can resolve per-user
+// credentials from it.
+type CalendarProvider interface {
+	// ListEvents returns the events overlapping [from, to), with recurring
+	// events expanded into single instances and ordered by start time.
+	ListEvents(ctx context.Context, from, to time.Time) ([]Event, error)
+	GetEvent(ctx context.Context, id string) (*Event, error)
+	InsertEvent(ctx context.Context, event *Event) (*Event, error)
+	// UpdateEvent overwrites the fields modelled by Event on the event with
+	// event.ID and leaves everything else (attendees, reminders...) intact.
+	// When event.ETag is set the write fails with ErrPreconditionFailed if
+	// the event changed since it was read.
+	UpdateEvent(ctx context.Context, event *Event) (*Event, error)
+	DeleteEvent(ctx context.Context, id string) error
+	// RespondToEvent sets the user's own response (accepted, declined or
+	// tentative) on an invitation and notifies the organizer.
+	RespondToEvent(ctx context.Context, id, response, comment string) (*Event, error)
+}
+
+// ErrPreconditionFailed is returned by UpdateEvent when the event was changed
+// by someone else after it was read.
+var ErrPreconditionFailed = errors.New("event was changed since it was read")
+
+// Event is a provider-neutral calendar event. All-day events start at midnight
+// and End is the exclusive day after the last day.
+type Event struct {
+	ID          string
+	UID         string
+	Calendar    string
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	HTMLLink    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	TimeZone    string
+	Locked      bool
+	Immovable   bool
+	Organizer   *Attendee
+	Attendees   []Attendee
+	// ConferenceURL is the video call link, if any.
+	ConferenceURL string
+	// Recurring is set on instances of recurring events.
+	Recurring bool
+	// ETag identifies the revision the event was read at.
+	ETag string
+}
+
+// Attendee is a participant of an event. ResponseStatus uses the Google
+// vocabulary: needsAction, accepted, declined or tentative.
+type Attendee struct {
+	Email          string
+	Name           string
+	ResponseStatus string
+	Self           bool
+	Optional       bool
+	Comment        string
+}
+
+// NewProviderFromEnv builds the provider selected by CALENDAR_PROVIDER
+// ("google", the default, or "caldav"). It returns nil when the selected
+// backend has no credentials configured.
+func NewProviderFromEnv(credFile string, oauthEnabled bool) (CalendarProvider, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("CALENDAR_PROVIDER"))); kind {
+	case "", "google":
+		if credFile == "" && !oauthEnabled {
+			return nil, nil
+		}
+		return NewGoogleProvider(credFile), nil
+	case "caldav":
+		url := strings.TrimSpace(os.Getenv("CALDAV_URL"))
+		if url == "" {
+			return nil, fmt.Errorf("CALDAV_URL is required when CALENDAR_PROVIDER=caldav")
+		}
+		return NewCalDAVProvider(
+			url,
+			os.Getenv("CALDAV_USERNAME"),
+			os.Getenv("CALDAV_PASSWORD"),
+			os.Getenv("CALDAV_EMAIL"),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown CALENDAR_PROVIDER %q; use google or caldav", kind)
+	}
+}
+
+// userTimeZone returns the time zone from the user's profile, or "" when the
+// server's zone should be used.
+func userTimeZone(ctx context.Context) string {
+	return profile.FromContext(ctx).TimeZone
+}
+
+// localEventTime converts timed events to the user's zone. All-day dates are
+// left alone because they are not instants.
+func localEventTime(ctx context.Context, t time.Time, allDay bool) time.Time {
+	if allDay || t.IsZero() {
+		return t
+	}
+	return t.In(profile.Location(ctx))
+}
+
+// formatEventTime renders an event boundary the way the tools report it:
+// YYYY-MM-DD for all-day events and RFC3339 otherwise.
+func formatEventTime(t time.Time, allDay bool) string {
+	if t.IsZero() {
+		return ""
+	}
+	if allDay {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,605 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"groundhog/internal/profile"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// immovableProperty marks events that must never be moved by a bulk reschedule.
+const immovableProperty = "groundhog_immovable"
+
+var immovableTags = []string{"#fixed", "#immovable"}
+
+// ShiftEvents moves the movable events of a time window, either by a fixed
+// amount or by rippling them after a newly inserted block.
+type ShiftEvents struct {
+	provider CalendarProvider
+	travel   *TravelPolicy
+}
+
+var _ tools.Tool = &ShiftEvents{}
+
+// NewShiftEvents returns the tool. travel may be nil to skip travel handling.
+func NewShiftEvents(provider CalendarProvider, travel *TravelPolicy) *ShiftEvents {
+	return &ShiftEvents{
+		provider: provider,
+		travel:   travel,
+	}
+}
+
+func (s *ShiftEvents) Name() string {
+	return "calendar_shift_events"
+}
+
+func (s *ShiftEvents) Description() string {
+	return `Reschedule the rest of the day in one step: shift every movable event in a time window by N minutes, or insert a new block and ripple the following events after it.
+
+Input must be a stringified JSON object like:
+{
+  "window_start": "2025-12-09T14:00:00-05:00",
+  "window_end": "2025-12-09T22:00:00-05:00",
+  "shift_minutes": 30
+}
+or
+{
+  "insert_start": "2025-12-09T15:30:00-05:00",
+  "insert_duration_minutes": 90,
+  "insert_summary": "Training"
+}
+
+Fields:
+- window_start (string, optional): RFC3339 start of the affected window. Defaults to now, or insert_start when inserting a block.
+- window_end (string, optional): RFC3339 end of the affected window. Defaults to the end of window_start's day.
+- shift_minutes (integer, optional): minutes to move every movable event starting in the window; negative values move events earlier.
+- insert_start (string, optional): RFC3339 start of a block to make room for.
+- insert_end (string, optional): RFC3339 end of the block; omit when using insert_duration_minutes.
+- insert_duration_minutes (integer, optional): block length in minutes.
+- insert_summary (string, optional): when set, the block is also created as an immovable calendar event with this title.
+- time_zone (string, optional): IANA name used for times without an offset; defaults to the user's time zone.
+- dry_run (boolean, optional): report the before/after diff without changing the calendar.
+
+Provide either shift_minutes or insert_start. Events with attendees, events organized by someone else, all-day events, events tagged #fixed or #immovable and occurrences of recurring events the calendar can't edit on their own are never moved.`
+}
+
+// Parameters exposes the structured schema for tool calling.
+func (s *ShiftEvents) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"window_start": map[string]interface{}{
+				"type":        "string",
+				"description": "RFC3339 start of the affected window; defaults to now or insert_start.",
+			},
+			"window_end": map[string]interface{}{
+				"type":        "string",
+				"description": "RFC3339 end of the affected window; defaults to the end of that day.",
+			},
+			"shift_minutes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Minutes to move movable events by; negative moves them earlier.",
+			},
+			"insert_start": map[string]interface{}{
+				"type":        "string",
+				"description": "RFC3339 start of a block to make room for.",
+			},
+			"insert_end": map[string]interface{}{
+				"type":        "string",
+				"description": "RFC3339 end of the inserted block.",
+			},
+			"insert_duration_minutes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Length of the inserted block when insert_end is omitted.",
+			},
+			"insert_summary": map[string]interface{}{
+				"type":        "string",
+				"description": "Create the inserted block as an event with this title.",
+			},
+			"time_zone": map[string]interface{}{
+				"type":        "string",
+				"description": "IANA time zone used for times without an offset.",
+			},
+			"dry_run": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only report the planned changes.",
+			},
+		},
+		"required": []string{},
+	}
+}
+
+func (s *ShiftEvents) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseShiftEventsInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(payload.TimeZone) == "" {
+		payload.TimeZone = userTimeZone(ctx)
+	}
+
+	plan, err := prepareShiftWindow(payload, time.Now().In(profile.Location(ctx)))
+	if err != nil {
+		return "", err
+	}
+
+	events, err := s.provider.ListEvents(ctx, plan.windowStart, plan.windowEnd)
+	if err != nil {
+		return "", err
+	}
+
+	canUpdate := func(Event) bool { return true }
+	if c, ok := unwrapCache(s.provider).(updateChecker); ok {
+		canUpdate = c.CanUpdate
+	}
+	result := planShift(plan, events, canUpdate)
+	for _, m := range result.moves {
+		if v := availabilityViolations(ctx, m.event.Summary, m.newStart, m.newEnd, isMeeting(m.event)); len(v) > 0 {
+			result.warnings = append(result.warnings, fmt.Sprintf("%q would move outside the user's availability: %s", m.event.Summary, strings.Join(v, "; ")))
+		}
+	}
+
+	var saved []Event
+	if !payload.DryRun {
+		if plan.insertSummary != "" {
+			block := &Event{
+				Summary:   plan.insertSummary,
+				Start:     plan.insertStart,
+				End:       plan.insertEnd,
+				TimeZone:  plan.timeZone,
+				Immovable: true,
+			}
+			created, err := s.provider.InsertEvent(ctx, block)
+			if err != nil {
+				return "", fmt.Errorf("unable to create block %q: %w", plan.insertSummary, err)
+			}
+			recordCreate(ctx, created)
+			saved = append(saved, *created)
+		}
+
+		var moved []eventMove
+		for _, m := range result.moves {
+			base, updated, err := s.moveEvent(ctx, m)
+			if errors.Is(err, errMovedElsewhere) {
+				result.kept = append(result.kept, keptEvent{
+					summary: m.event.Summary,
+					id:      m.event.ID,
+					start:   formatEventTime(m.event.Start, false),
+					reason:  "moved elsewhere in the meantime",
+				})
+				continue
+			}
+			if err != nil {
+				// Earlier events are already moved, so report the failure
+				// with them instead of hiding what changed.
+				result.kept = append(result.kept, keptEvent{
+					summary: m.event.Summary,
+					id:      m.event.ID,
+					start:   formatEventTime(m.event.Start, false),
+					reason:  "couldn't be moved: " + err.Error(),
+				})
+				result.warnings = append(result.warnings, fmt.Sprintf("%q couldn't be moved and may now overlap the events moved after it", m.event.Summary))
+				continue
+			}
+			recordUpdate(ctx, base, updated)
+			saved = append(saved, *updated)
+			moved = append(moved, m)
+		}
+		result.moves = moved
+	}
+
+	res := formatShiftResult(plan, result, payload.DryRun)
+	for _, line := range s.travel.travelNotes(ctx, s.provider, saved...) {
+		res += "\n" + line
+	}
+	return res, nil
+}
+
+// updateChecker is implemented by providers that can't update every event
+// they list.
+type updateChecker interface {
+	CanUpdate(e Event) bool
+}
+
+// errMovedElsewhere reports an event whose times changed after the shift
+// was planned.
+var errMovedElsewhere = errors.New("event moved elsewhere")
+
+// moveEvent writes the new times of m and returns the version it replaced
+// with the saved one. Like EditEvent, it re-applies the move on top of an
+// event that changed since it was listed, unless its times changed too.
+func (s *ShiftEvents) moveEvent(ctx context.Context, m eventMove) (*Event, *Event, error) {
+	base := m.event
+	for attempt := 1; ; attempt++ {
+		moved := base
+		moved.Start, moved.End = m.newStart, m.newEnd
+		saved, err := s.provider.UpdateEvent(ctx, &moved)
+		if errors.Is(err, ErrPreconditionFailed) && attempt < maxEditAttempts {
+			latest, err := s.provider.GetEvent(ctx, m.event.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !latest.Start.Equal(m.event.Start) || !latest.End.Equal(m.event.End) {
+				return nil, nil, errMovedElsewhere
+			}
+			base = *latest
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return &base, saved, nil
+	}
+}
+
+// Describe previews the shift by running it as a dry run.
+func (s *ShiftEvents) Describe(ctx context.Context, input string) (string, error) {
+	payload, err := parseShiftEventsInput(input)
+	if err != nil {
+		return "", err
+	}
+	payload.DryRun = true
+	preview, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return s.Call(ctx, string(preview))
+}
+
+// DryRun reports whether input only asks for a preview.
+func (s *ShiftEvents) DryRun(input string) bool {
+	payload, err := parseShiftEventsInput(input)
+	return err == nil && payload.DryRun
+}
+
+// Resolve fixes the window and inserted block to absolute times, so a
+// default window starting now doesn't move before the shift is applied.
+func (s *ShiftEvents) Resolve(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseShiftEventsInput(input)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(payload.TimeZone) == "" {
+		payload.TimeZone = userTimeZone(ctx)
+	}
+	plan, err := prepareShiftWindow(payload, time.Now().In(profile.Location(ctx)))
+	if err != nil {
+		return "", err
+	}
+	payload.WindowStart = plan.windowStart.Format(time.RFC3339)
+	payload.WindowEnd = plan.windowEnd.Format(time.RFC3339)
+	if plan.inserting {
+		payload.InsertStart = plan.insertStart.Format(time.RFC3339)
+		payload.InsertEnd = plan.insertEnd.Format(time.RFC3339)
+		payload.InsertDurationMinutes = 0
+	}
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
+}
+
+type shiftEventsInput struct {
+	WindowStart           string `json:"window_start,omitempty"`
+	WindowEnd             string `json:"window_end,omitempty"`
+	ShiftMinutes          *int   `json:"shift_minutes,omitempty"`
+	InsertStart           string `json:"insert_start,omitempty"`
+	InsertEnd             string `json:"insert_end,omitempty"`
+	InsertDurationMinutes int    `json:"insert_duration_minutes,omitempty"`
+	InsertSummary         string `json:"insert_summary,omitempty"`
+	TimeZone              string `json:"time_zone,omitempty"`
+	DryRun                bool   `json:"dry_run,omitempty"`
+}
+
+func parseShiftEventsInput(raw string) (shiftEventsInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return shiftEventsInput{}, fmt.Errorf("provide shift details as a JSON object in the tool input")
+	}
+
+	var payload shiftEventsInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return shiftEventsInput{}, fmt.Errorf("invalid shift events payload; expected a JSON object: %w", err)
+	}
+
+	inserting := strings.TrimSpace(payload.InsertStart) != ""
+	if payload.ShiftMinutes == nil && !inserting {
+		return shiftEventsInput{}, fmt.Errorf("provide either shift_minutes or insert_start")
+	}
+	if payload.ShiftMinutes != nil && inserting {
+		return shiftEventsInput{}, fmt.Errorf("shift_minutes and insert_start cannot be combined")
+	}
+	if payload.ShiftMinutes != nil && *payload.ShiftMinutes == 0 {
+		return shiftEventsInput{}, fmt.Errorf("shift_minutes must not be 0")
+	}
+	if payload.InsertDurationMinutes < 0 {
+		return shiftEventsInput{}, fmt.Errorf("insert_duration_minutes must be greater than 0")
+	}
+	if !inserting && (payload.InsertEnd != "" || payload.InsertDurationMinutes != 0 || payload.InsertSummary != "") {
+		return shiftEventsInput{}, fmt.Errorf("insert_end, insert_duration_minutes and insert_summary require insert_start")
+	}
+
+	return payload, nil
+}
+
+// shiftWindow is the validated, time-resolved form of shiftEventsInput.
+type shiftWindow struct {
+	windowStart   time.Time
+	windowEnd     time.Time
+	shift         time.Duration
+	inserting     bool
+	insertStart   time.Time
+	insertEnd     time.Time
+	insertSummary string
+	timeZone      string
+}
+
+func prepareShiftWindow(in shiftEventsInput, now time.Time) (shiftWindow, error) {
+	tz := strings.TrimSpace(in.TimeZone)
+	plan := shiftWindow{
+		insertSummary: strings.TrimSpace(in.InsertSummary),
+		timeZone:      tz,
+	}
+
+	if in.ShiftMinutes != nil {
+		plan.shift = time.Duration(*in.ShiftMinutes) * time.Minute
+	} else {
+		plan.inserting = true
+		start, end, allDay, _, err := prepareEventTimes(addEventInput{
+			StartTime:       in.InsertStart,
+			EndTime:         in.InsertEnd,
+			DurationMinutes: in.InsertDurationMinutes,
+			TimeZone:        tz,
+		})
+		if err != nil {
+			return shiftWindow{}, fmt.Errorf("invalid inserted block: %w", err)
+		}
+		if allDay {
+			return shiftWindow{}, fmt.Errorf("insert_start must include a time of day")
+		}
+		plan.insertStart, plan.insertEnd = start, end
+	}
+
+	plan.windowStart = now
+	if plan.inserting {
+		plan.windowStart = plan.insertStart
+	}
+	if in.WindowStart != "" {
+		start, allDay, err := parseTime(in.WindowStart, tz)
+		if err != nil {
+			return shiftWindow{}, fmt.Errorf("invalid window_start: %w", err)
+		}
+		if allDay {
+			return shiftWindow{}, fmt.Errorf("window_start must include a time of day")
+		}
+		plan.windowStart = start
+	}
+
+	y, m, d := plan.windowStart.Date()
+	plan.windowEnd = time.Date(y, m, d+1, 0, 0, 0, 0, plan.windowStart.Location())
+	if in.WindowEnd != "" {
+		end, allDay, err := parseTime(in.WindowEnd, tz)
+		if err != nil {
+			return shiftWindow{}, fmt.Errorf("invalid window_end: %w", err)
+		}
+		if allDay {
+			return shiftWindow{}, fmt.Errorf("window_end must include a time of day")
+		}
+		plan.windowEnd = end
+	}
+	if !plan.windowEnd.After(plan.windowStart) {
+		return shiftWindow{}, fmt.Errorf("window_end must be after window_start")
+	}
+
+	return plan, nil
+}
+
+type eventMove struct {
+	event    Event
+	newStart time.Time
+	newEnd   time.Time
+}
+
+type keptEvent struct {
+	summary string
+	id      string
+	start   string
+	reason  string
+}
+
+type shiftResult struct {
+	moves    []eventMove
+	kept     []keptEvent
+	warnings []string
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+func (i interval) overlaps(o interval) bool {
+	return i.start.Before(o.end) && o.start.Before(i.end)
+}
+
+// planShift decides the new position of every movable event without touching
+// the calendar, so dry runs and real runs report the same diff. Events
+// canUpdate rejects stay in place like fixed ones.
+func planShift(plan shiftWindow, events []Event, canUpdate func(Event) bool) shiftResult {
+	var result shiftResult
+	var movable []eventMove
+	var fixed []interval
+
+	for _, e := range events {
+		if e.Status == "cancelled" {
+			continue
+		}
+		startStr := formatEventTime(e.Start, e.AllDay)
+		if e.AllDay {
+			result.kept = append(result.kept, keptEvent{summary: e.Summary, id: e.ID, start: startStr, reason: "all-day"})
+			continue
+		}
+		if plan.inserting && e.Start.Before(plan.insertStart) {
+			if e.End.After(plan.insertStart) {
+				result.warnings = append(result.warnings, fmt.Sprintf("%q is already in progress and overlaps the inserted block", e.Summary))
+			}
+			continue
+		}
+		if e.Start.Before(plan.windowStart) {
+			continue
+		}
+		if reason := fixedReason(e); reason != "" {
+			fixed = append(fixed, interval{start: e.Start, end: e.End})
+			result.kept = append(result.kept, keptEvent{summary: e.Summary, id: e.ID, start: startStr, reason: reason})
+			continue
+		}
+		if !canUpdate(e) {
+			fixed = append(fixed, interval{start: e.Start, end: e.End})
+			result.kept = append(result.kept, keptEvent{summary: e.Summary, id: e.ID, start: startStr, reason: "single occurrence of a recurring event, which this calendar can't move on its own"})
+			continue
+		}
+		movable = append(movable, eventMove{event: e})
+	}
+
+	sort.SliceStable(movable, func(i, j int) bool {
+		return movable[i].event.Start.Before(movable[j].event.Start)
+	})
+
+	if !plan.inserting {
+		for _, m := range movable {
+			m.newStart = m.event.Start.Add(plan.shift)
+			m.newEnd = m.event.End.Add(plan.shift)
+			for _, f := range fixed {
+				if (interval{start: m.newStart, end: m.newEnd}).overlaps(f) {
+					result.warnings = append(result.warnings, fmt.Sprintf("%q now overlaps a fixed event", m.event.Summary))
+					break
+				}
+			}
+			result.moves = append(result.moves, m)
+		}
+		return result
+	}
+
+	// Ripple: every movable event that would start before the cursor is pushed
+	// to the cursor, skipping over fixed events, keeping its duration.
+	cursor := plan.insertEnd
+	block := interval{start: plan.insertStart, end: plan.insertEnd}
+	for _, f := range fixed {
+		if f.overlaps(block) {
+			result.warnings = append(result.warnings, "the inserted block overlaps a fixed event")
+			break
+		}
+	}
+	for _, m := range movable {
+		if !m.event.Start.Before(cursor) {
+			if m.event.End.After(cursor) {
+				cursor = m.event.End
+			}
+			continue
+		}
+		duration := m.event.End.Sub(m.event.Start)
+		start := cursor
+		for moved := true; moved; {
+			moved = false
+			for _, f := range fixed {
+				if (interval{start: start, end: start.Add(duration)}).overlaps(f) {
+					start = f.end
+					moved = true
+				}
+			}
+		}
+		m.newStart = start
+		m.newEnd = start.Add(duration)
+		cursor = m.newEnd
+		if m.newEnd.After(plan.windowEnd) {
+			result.warnings = append(result.warnings, fmt.Sprintf("%q now ends after the window", m.event.Summary))
+		}
+		result.moves = append(result.moves, m)
+	}
+	return result
+}
+
+// fixedReason reports why an event must stay in place, or "" when it can move.
+func fixedReason(e Event) string {
+	if e.Locked {
+		return "locked"
+	}
+	if e.Immovable {
+		return "tagged immovable"
+	}
+	text := strings.ToLower(e.Summary + " " + e.Description)
+	for _, tag := range immovableTags {
+		if strings.Contains(text, tag) {
+			return "tagged " + tag
+		}
+	}
+	if e.Organizer != nil && !e.Organizer.Self {
+		return "organized by someone else"
+	}
+	for _, a := range e.Attendees {
+		if !a.Self {
+			return "has attendees"
+		}
+	}
+	return ""
+}
+
+func formatShiftResult(plan shiftWindow, result shiftResult, dryRun bool) string {
+	var b strings.Builder
+
+	if plan.inserting {
+		title := plan.insertSummary
+		if title == "" {
+			title = "block"
+		}
+		verb := "Inserted"
+		if dryRun || plan.insertSummary == "" {
+			verb = "Made room for"
+		}
+		b.WriteString(fmt.Sprintf("%s %s (%s → %s).\n", verb, title, plan.insertStart.Format(time.RFC3339), plan.insertEnd.Format(time.RFC3339)))
+	}
+
+	switch {
+	case len(result.moves) == 0:
+		b.WriteString("No events needed to move.\n")
+	case dryRun:
+		b.WriteString(fmt.Sprintf("Would move %d event(s) (dry run, nothing changed):\n", len(result.moves)))
+	default:
+		b.WriteString(fmt.Sprintf("Moved %d event(s):\n", len(result.moves)))
+	}
+	for _, m := range result.moves {
+		b.WriteString(fmt.Sprintf("- \"%s\" %s → %s  ⇒  %s → %s (id: %s)\n",
+			m.event.Summary,
+			m.event.Start.Format(time.RFC3339), m.event.End.Format(time.RFC3339),
+			m.newStart.Format(time.RFC3339), m.newEnd.Format(time.RFC3339),
+			m.event.ID))
+	}
+
+	if len(result.kept) > 0 {
+		b.WriteString("Kept in place:\n")
+		for _, k := range result.kept {
+			b.WriteString(fmt.Sprintf("- \"%s\" %s (%s, id: %s)\n", k.summary, k.start, k.reason, k.id))
+		}
+	}
+
+	if len(result.warnings) > 0 {
+		b.WriteString("Warnings:\n")
+		for _, w := range result.warnings {
+			b.WriteString("- " + w + "\n")
+		}
+	}
+
+	return b.String()
+}
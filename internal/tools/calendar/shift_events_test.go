@@ -0,0 +1,75 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanShift(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, time.December, 9, hour, minute, 0, 0, time.UTC)
+	}
+	event := func(id, summary string, start, end time.Time) Event {
+		return Event{ID: id, Summary: summary, Start: start, End: end}
+	}
+	caldav := &CalDAVProvider{}
+	occurrence := event("/cal/gym.ics#20251209T110000Z", "Gym", at(11, 0), at(11, 30))
+	occurrence.Recurring = true
+	series := event("/cal/review.ics", "Review", at(14, 0), at(14, 30))
+	series.Recurring = true
+
+	tests := []struct {
+		name   string
+		plan   shiftWindow
+		events []Event
+		// moves are "summary hh:mm-hh:mm"; kept are "summary (reason)".
+		moves []string
+		kept  []string
+	}{
+		{
+			name: "shift keeps fixed and all-day events",
+			plan: shiftWindow{windowStart: at(9, 0), windowEnd: at(18, 0), shift: 30 * time.Minute},
+			events: []Event{
+				event("a", "Write", at(10, 0), at(11, 0)),
+				event("b", "Dentist #fixed", at(12, 0), at(13, 0)),
+				{ID: "c", Summary: "Holiday", Start: at(0, 0), End: at(0, 0).AddDate(0, 0, 1), AllDay: true},
+				{ID: "d", Summary: "Dropped", Start: at(15, 0), End: at(16, 0), Status: "cancelled"},
+				event("e", "Earlier", at(8, 0), at(8, 30)),
+			},
+			moves: []string{"Write 10:30-11:30"},
+			kept:  []string{"Dentist #fixed (tagged #fixed)", "Holiday (all-day)"},
+		},
+		{
+			name: "ripple skips an occurrence the calendar can't move",
+			plan: shiftWindow{windowStart: at(10, 0), windowEnd: at(18, 0), inserting: true, insertStart: at(10, 0), insertEnd: at(11, 0)},
+			events: []Event{
+				event("a", "Write", at(10, 0), at(10, 30)),
+				event("c", "Email", at(10, 30), at(11, 0)),
+				occurrence,
+				series,
+			},
+			moves: []string{"Write 11:30-12:00", "Email 12:00-12:30"},
+			kept:  []string{"Gym (single occurrence of a recurring event, which this calendar can't move on its own)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := planShift(tt.plan, tt.events, caldav.CanUpdate)
+
+			var moves, kept []string
+			for _, m := range result.moves {
+				moves = append(moves, m.event.Summary+" "+m.newStart.Format("15:04")+"-"+m.newEnd.Format("15:04"))
+			}
+			for _, k := range result.kept {
+				kept = append(kept, k.summary+" ("+k.reason+")")
+			}
+			if !reflect.DeepEqual(moves, tt.moves) {
+				t.Errorf("moves = %q, want %q", moves, tt.moves)
+			}
+			if !reflect.DeepEqual(kept, tt.kept) {
+				t.Errorf("kept = %q, want %q", kept, tt.kept)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// TravelOff disables travel handling.
+	TravelOff = "off"
+	// TravelWarn only reports missing travel time.
+	TravelWarn = "warn"
+	// TravelInsert creates Travel/Buffer events in front of the later event.
+	TravelInsert = "insert"
+
+	// travelAdjacency is how close two events must be to count as neighbours.
+	travelAdjacency = 3 * time.Hour
+)
+
+var virtualLocations = []string{"zoom", "meet", "teams", "online", "remote", "phone", "call", "skype", "webex"}
+
+// TravelRoute is the travel time between two locations, in either direction.
+type TravelRoute struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Minutes int    `json:"minutes"`
+}
+
+// TravelPolicy decides how much time to keep between events at different
+// locations.
+type TravelPolicy struct {
+	Mode string `json:"mode"`
+	// BufferMinutes applies to location pairs missing from Routes.
+	BufferMinutes int           `json:"buffer_minutes"`
+	Routes        []TravelRoute `json:"routes"`
+}
+
+// DefaultTravelPolicy warns about less than 15 minutes between locations.
+func DefaultTravelPolicy() *TravelPolicy {
+	return &TravelPolicy{
+		Mode:          TravelWarn,
+		BufferMinutes: 15,
+	}
+}
+
+// LoadTravelPolicy reads a policy from a JSON file such as:
+//
+//	{
+//	  "mode": "insert",
+//	  "buffer_minutes": 10,
+//	  "routes": [{"from": "Office", "to": "Gym", "minutes": 25}]
+//	}
+//
+// A missing file yields DefaultTravelPolicy.
+func LoadTravelPolicy(path string) (*TravelPolicy, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultTravelPolicy(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read travel policy: %w", err)
+	}
+
+	p := DefaultTravelPolicy()
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("couldn't parse travel policy in %s: %w", path, err)
+	}
+	switch p.Mode {
+	case TravelOff, TravelWarn, TravelInsert:
+	default:
+		return nil, fmt.Errorf("travel policy mode must be %q, %q or %q", TravelOff, TravelWarn, TravelInsert)
+	}
+	if p.BufferMinutes < 0 {
+		return nil, fmt.Errorf("buffer_minutes must not be negative")
+	}
+	for _, r := range p.Routes {
+		if r.Minutes < 0 {
+			return nil, fmt.Errorf("route %s → %s: minutes must not be negative", r.From, r.To)
+		}
+	}
+	return p, nil
+}
+
+// needed returns the time to keep between from and to, and whether it came
+// from the route table. Virtual and unknown locations need nothing.
+func (p *TravelPolicy) needed(from, to string) (time.Duration, bool) {
+	a, b := normalizeLocation(from), normalizeLocation(to)
+	if a == "" || b == "" || a == b || isVirtualLocation(a) || isVirtualLocation(b) {
+		return 0, false
+	}
+	for _, r := range p.Routes {
+		ra, rb := normalizeLocation(r.From), normalizeLocation(r.To)
+		if (ra == a && rb == b) || (ra == b && rb == a) {
+			return time.Duration(r.Minutes) * time.Minute, true
+		}
+	}
+	return time.Duration(p.BufferMinutes) * time.Minute, false
+}
+
+// travelGap is the time between two neighbouring events that needs covering.
+type travelGap struct {
+	before, after Event
+	needed        time.Duration
+	routed        bool
+}
+
+func (g travelGap) title() string {
+	if g.routed {
+		return fmt.Sprintf("Travel: %s → %s", g.before.Location, g.after.Location)
+	}
+	return "Buffer"
+}
+
+// planTravel finds the neighbours of e that are at a different location and
+// too close or not yet separated by a travel block.
+func (p *TravelPolicy) planTravel(e Event, events []Event) []travelGap {
+	if p == nil || p.Mode == TravelOff || e.AllDay {
+		return nil
+	}
+
+	var prev, next *Event
+	for i := range events {
+		o := events[i]
+		if o.ID == e.ID || o.AllDay || o.Status == "cancelled" {
+			continue
+		}
+		if !o.End.After(e.Start) && e.Start.Sub(o.End) <= travelAdjacency {
+			if prev == nil || o.End.After(prev.End) {
+				prev = &events[i]
+			}
+		}
+		if !o.Start.Before(e.End) && o.Start.Sub(e.End) <= travelAdjacency {
+			if next == nil || o.Start.Before(next.Start) {
+				next = &events[i]
+			}
+		}
+	}
+
+	var gaps []travelGap
+	for _, pair := range [][2]*Event{{prev, &e}, {&e, next}} {
+		if pair[0] == nil || pair[1] == nil || isTravelEvent(*pair[0]) || isTravelEvent(*pair[1]) {
+			continue
+		}
+		needed, routed := p.needed(pair[0].Location, pair[1].Location)
+		if needed <= 0 || hasTravelBlock(events, pair[0].End, pair[1].Start) {
+			continue
+		}
+		gaps = append(gaps, travelGap{before: *pair[0], after: *pair[1], needed: needed, routed: routed})
+	}
+	return gaps
+}
+
+// applyTravel warns about or fills the gaps around a newly added or moved
+// event and returns one line per gap for the tool output.
+func (p *TravelPolicy) applyTravel(ctx context.Context, provider CalendarProvider, e Event) ([]string, error) {
+	if p == nil || p.Mode == TravelOff || e.AllDay {
+		return nil, nil
+	}
+	events, err := provider.ListEvents(ctx, e.Start.Add(-travelAdjacency), e.End.Add(travelAdjacency))
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, g := range p.planTravel(e, events) {
+		gap := g.after.Start.Sub(g.before.End)
+		if gap < g.needed {
+			lines = append(lines, fmt.Sprintf("Warning: %d min between \"%s\" (%s) and \"%s\" (%s); %d min needed for %s.",
+				int(gap.Minutes()), g.before.Summary, g.before.Location, g.after.Summary, g.after.Location,
+				int(g.needed.Minutes()), strings.ToLower(g.title())))
+			continue
+		}
+		if p.Mode == TravelWarn {
+			continue
+		}
+
+		block, err := provider.InsertEvent(ctx, &Event{
+			Summary:  g.title(),
+			Start:    g.after.Start.Add(-g.needed),
+			End:      g.after.Start,
+			TimeZone: g.after.TimeZone,
+		})
+		if err != nil {
+			return lines, fmt.Errorf("unable to add %s block: %w", strings.ToLower(g.title()), err)
+		}
+		recordCreate(ctx, block)
+		lines = append(lines, fmt.Sprintf("Added \"%s\" (%s → %s, id: %s).", block.Summary,
+			formatEventTime(localEventTime(ctx, block.Start, false), false),
+			formatEventTime(localEventTime(ctx, block.End, false), false), block.ID))
+	}
+	return lines, nil
+}
+
+// travelNotes runs applyTravel for each of the saved events and returns
+// their lines without repeats, as neighbouring events share a gap. The
+// events exist at this point, so travel problems are reported rather than
+// failing the call.
+func (p *TravelPolicy) travelNotes(ctx context.Context, provider CalendarProvider, events ...Event) []string {
+	var notes []string
+	seen := make(map[string]bool)
+	for _, e := range events {
+		lines, err := p.applyTravel(ctx, provider, e)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("Warning: couldn't check travel time: %v", err))
+		}
+		for _, line := range lines {
+			if !seen[line] {
+				seen[line] = true
+				notes = append(notes, line)
+			}
+		}
+	}
+	return notes
+}
+
+func isTravelEvent(e Event) bool {
+	s := strings.ToLower(strings.TrimSpace(e.Summary))
+	return s == "buffer" || strings.HasPrefix(s, "travel")
+}
+
+func hasTravelBlock(events []Event, from, to time.Time) bool {
+	for _, e := range events {
+		if isTravelEvent(e) && e.End.After(from) && e.Start.Before(to) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeLocation(location string) string {
+	return strings.Join(strings.Fields(strings.ToLower(location)), " ")
+}
+
+func isVirtualLocation(location string) bool {
+	if strings.Contains(location, "://") {
+		return true
+	}
+	for _, v := range virtualLocations {
+		if location == v || strings.HasPrefix(location, v+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"groundhog/internal/oplog"
+)
+
+const (
+	opCreateEvent = "calendar.create"
+	opUpdateEvent = "calendar.update"
+	opRespond     = "calendar.respond"
+)
+
+// recordCreate logs a new event so it can be undone. Logging failures don't
+// fail the tool because the calendar has already changed.
+func recordCreate(ctx context.Context, created *Event) {
+	if err := oplog.Record(ctx, opCreateEvent, created.ID, fmt.Sprintf("Create event %q", created.Summary), nil, created); err != nil {
+		log.Println("Couldn't record calendar change:", err)
+	}
+}
+
+func recordUpdate(ctx context.Context, before, after *Event) {
+	if err := oplog.Record(ctx, opUpdateEvent, after.ID, fmt.Sprintf("Edit event %q", before.Summary), before, after); err != nil {
+		log.Println("Couldn't record calendar change:", err)
+	}
+}
+
+func recordResponse(ctx context.Context, before, after *Event) {
+	summary := fmt.Sprintf("Answer %q with %s", before.Summary, selfResponse(after))
+	if err := oplog.Record(ctx, opRespond, after.ID, summary, before, after); err != nil {
+		log.Println("Couldn't record calendar change:", err)
+	}
+}
+
+// Reverter undoes logged calendar operations.
+type Reverter struct {
+	provider CalendarProvider
+}
+
+var _ oplog.Reverter = &Reverter{}
+
+func NewReverter(provider CalendarProvider) *Reverter {
+	return &Reverter{
+		provider: provider,
+	}
+}
+
+func (r *Reverter) Revert(ctx context.Context, op oplog.Operation) error {
+	switch op.Kind {
+	case opCreateEvent:
+		var after Event
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged event: %w", err)
+		}
+		current, err := r.provider.GetEvent(ctx, op.Target)
+		if err != nil {
+			return err
+		}
+		if !sameEventFields(current, &after) || (after.ETag != "" && current.ETag != after.ETag) {
+			return fmt.Errorf("event %q was changed since it was created; delete it directly instead", current.Summary)
+		}
+		return r.provider.DeleteEvent(ctx, op.Target)
+	case opUpdateEvent:
+		var before, after Event
+		if err := json.Unmarshal(op.Before, &before); err != nil {
+			return fmt.Errorf("invalid logged event: %w", err)
+		}
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged event: %w", err)
+		}
+		current, err := r.provider.GetEvent(ctx, op.Target)
+		if err != nil {
+			return err
+		}
+		if !sameEventFields(current, &after) {
+			return fmt.Errorf("event %q was changed again since; edit it directly instead", current.Summary)
+		}
+		before.ETag = current.ETag
+		_, err = r.provider.UpdateEvent(ctx, &before)
+		return err
+	case opRespond:
+		var before, after Event
+		if err := json.Unmarshal(op.Before, &before); err != nil {
+			return fmt.Errorf("invalid logged event: %w", err)
+		}
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged event: %w", err)
+		}
+		current, err := r.provider.GetEvent(ctx, op.Target)
+		if err != nil {
+			return err
+		}
+		if selfResponse(current) != selfResponse(&after) {
+			return fmt.Errorf("the answer to %q was changed again since; respond to it directly instead", current.Summary)
+		}
+		prev, _ := selfAttendee(&before)
+		_, err = r.provider.RespondToEvent(ctx, op.Target, prev.ResponseStatus, prev.Comment)
+		return err
+	default:
+		return fmt.Errorf("unknown calendar operation %q", op.Kind)
+	}
+}
+
+// sameEventFields compares the fields our tools edit.
+func sameEventFields(a, b *Event) bool {
+	return a.Summary == b.Summary &&
+		a.Description == b.Description &&
+		a.Location == b.Location &&
+		a.AllDay == b.AllDay &&
+		a.Start.Equal(b.Start) &&
+		a.End.Equal(b.End)
+}
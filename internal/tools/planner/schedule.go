@@ -0,0 +1,320 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"groundhog/internal/nldate"
+	"groundhog/internal/profile"
+	"groundhog/internal/scheduler"
+	"groundhog/internal/tools/calendar"
+	"groundhog/internal/tools/tasks"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ScheduleTasks turns open tasks into non-overlapping time blocks around the
+// fixed events of a day. It only proposes the blocks; the agent writes them
+// to the calendar with calendar_add_event.
+type ScheduleTasks struct {
+	provider calendar.CalendarProvider
+	tasks    *tasks.ListTasks
+}
+
+var _ tools.Tool = &ScheduleTasks{}
+
+// NewScheduleTasks returns the tool. taskLister may be nil when Google Tasks
+// is not configured; then only tasks passed in the input are scheduled.
+func NewScheduleTasks(provider calendar.CalendarProvider, taskLister *tasks.ListTasks) *ScheduleTasks {
+	return &ScheduleTasks{
+		provider: provider,
+		tasks:    taskLister,
+	}
+}
+
+func (s *ScheduleTasks) Name() string {
+	return "schedule_tasks"
+}
+
+func (s *ScheduleTasks) Description() string {
+	return `Compute a time-blocked plan for a day from open tasks and the fixed calendar events. The result is deterministic; write the returned blocks to the calendar with calendar_add_event (focus_block: true) after the user agrees.
+
+Input is a stringified JSON object like:
+{
+  "date": "tomorrow",
+  "mode": "flexible",
+  "working_start": "09:00",
+  "working_end": "17:00",
+  "tasks": [{"title": "Write report", "estimate_minutes": 90, "priority": 1}]
+}
+
+Fields:
+- date (string, optional): YYYY-MM-DD or an expression like "tomorrow"; defaults to today.
+- mode (string, optional): "strict" plans each task for exactly its estimate back to back; "flexible" (default) adds 25% slack, rounds to 5 minutes and leaves 5-minute breaks.
+- working_start, working_end (string, optional): HH:MM working hours; default the user's working hours for that day, or 09:00-17:00. Lunch and sleep from the user's availability are kept free.
+- tasks (array, optional): extra tasks with title, estimate_minutes and priority (1 = most important).
+- include_google_tasks (boolean, optional): also plan open Google Tasks due by that day; default true.
+- task_list_id (string, optional): id or name of the Google Tasks list to read.
+
+Google Tasks use the estimate_minutes and priority stored by tasks_add and tasks_update; tasks without an estimate get 30 minutes.`
+}
+
+func (s *ScheduleTasks) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"date": map[string]interface{}{
+				"type":        "string",
+				"description": "Day to plan: YYYY-MM-DD or a relative expression like \"tomorrow\"; defaults to today.",
+			},
+			"mode": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"strict", "flexible"},
+				"description": "strict = minute-exact, flexible = slack and breaks added (default).",
+			},
+			"working_start": map[string]interface{}{
+				"type":        "string",
+				"description": "Start of working hours, HH:MM (default 09:00).",
+			},
+			"working_end": map[string]interface{}{
+				"type":        "string",
+				"description": "End of working hours, HH:MM (default 17:00).",
+			},
+			"tasks": map[string]interface{}{
+				"type":        "array",
+				"description": "Additional tasks to plan.",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"title":            map[string]interface{}{"type": "string"},
+						"estimate_minutes": map[string]interface{}{"type": "integer"},
+						"priority":         map[string]interface{}{"type": "integer"},
+					},
+					"required": []string{"title"},
+				},
+			},
+			"include_google_tasks": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Plan open Google Tasks due by that day too (default true).",
+			},
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Google Tasks list id or name; omit for the default list.",
+			},
+		},
+		"required": []string{},
+	}
+}
+
+func (s *ScheduleTasks) Call(ctx context.Context, input string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseScheduleInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	userProfile := profile.FromContext(ctx)
+	loc := userProfile.Location()
+	now := time.Now().In(loc)
+	day, err := resolveDay(payload.Date, now)
+	if err != nil {
+		return "", err
+	}
+
+	// Explicit hours win; otherwise the user's availability, then 09:00-17:00.
+	defaultStart, defaultEnd := "09:00", "17:00"
+	if payload.WorkingStart == "" && payload.WorkingEnd == "" && len(userProfile.Availability.WorkingHours) > 0 {
+		w, ok := userProfile.Availability.Working(day)
+		if !ok {
+			return fmt.Sprintf("%s is a day off in the user's availability; pass working_start and working_end to plan it anyway.", day.Format("Monday, 02 Jan")), nil
+		}
+		defaultStart, defaultEnd = w.Start.Format("15:04"), w.End.Format("15:04")
+		if w.End.Day() != day.Day() {
+			defaultEnd = "24:00"
+		}
+	}
+	workStart, err := atClock(day, payload.WorkingStart, defaultStart)
+	if err != nil {
+		return "", fmt.Errorf("invalid working_start: %w", err)
+	}
+	workEnd, err := atClock(day, payload.WorkingEnd, defaultEnd)
+	if err != nil {
+		return "", fmt.Errorf("invalid working_end: %w", err)
+	}
+	if workStart.Before(now) {
+		workStart = now
+	}
+	if !workEnd.After(workStart) {
+		return "No working time left on that day.", nil
+	}
+
+	req := scheduler.Request{
+		Mode:    scheduler.Mode(payload.Mode),
+		Working: []scheduler.Interval{{Start: workStart, End: workEnd}},
+	}
+
+	if req.Busy, err = busyIntervals(ctx, s.provider, userProfile.Availability, day); err != nil {
+		return "", err
+	}
+
+	for i, t := range payload.Tasks {
+		req.Tasks = append(req.Tasks, scheduler.Task{
+			ID:       fmt.Sprintf("input-%d", i+1),
+			Title:    strings.TrimSpace(t.Title),
+			Estimate: time.Duration(t.EstimateMinutes) * time.Minute,
+			Priority: t.Priority,
+		})
+	}
+	if s.tasks != nil && (payload.IncludeGoogleTasks == nil || *payload.IncludeGoogleTasks) {
+		open, _, err := s.tasks.Fetch(ctx, tasks.TaskQuery{
+			TaskListID: payload.TaskListID,
+			Status:     "needsAction",
+		})
+		if err != nil {
+			return "", err
+		}
+		lastDay := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		for _, t := range open {
+			if t.Status != "needsAction" || (!t.Due.IsZero() && t.Due.After(lastDay)) {
+				continue
+			}
+			req.Tasks = append(req.Tasks, scheduler.Task{
+				ID:       t.ID,
+				Title:    t.Title,
+				Estimate: t.Meta.Estimate(),
+				Priority: t.Meta.Priority,
+				Due:      t.Due,
+			})
+		}
+	}
+	if len(req.Tasks) == 0 {
+		return "There are no open tasks to plan.", nil
+	}
+
+	return formatPlan(req.Mode, scheduler.Schedule(req), loc), nil
+}
+
+type scheduleInput struct {
+	Date               string         `json:"date"`
+	Mode               string         `json:"mode"`
+	WorkingStart       string         `json:"working_start"`
+	WorkingEnd         string         `json:"working_end"`
+	Tasks              []scheduleTask `json:"tasks"`
+	IncludeGoogleTasks *bool          `json:"include_google_tasks"`
+	TaskListID         string         `json:"task_list_id"`
+}
+
+type scheduleTask struct {
+	Title           string `json:"title"`
+	EstimateMinutes int    `json:"estimate_minutes"`
+	Priority        int    `json:"priority"`
+}
+
+func parseScheduleInput(raw string) (scheduleInput, error) {
+	var payload scheduleInput
+	if trimmed := strings.TrimSpace(raw); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+			return scheduleInput{}, fmt.Errorf("invalid schedule payload; expected a JSON object: %w", err)
+		}
+	}
+
+	switch payload.Mode {
+	case "":
+		payload.Mode = string(scheduler.Flexible)
+	case string(scheduler.Strict), string(scheduler.Flexible):
+	default:
+		return scheduleInput{}, fmt.Errorf("mode must be \"strict\" or \"flexible\"")
+	}
+	for _, t := range payload.Tasks {
+		if strings.TrimSpace(t.Title) == "" {
+			return scheduleInput{}, fmt.Errorf("every task needs a title")
+		}
+		if t.EstimateMinutes < 0 || t.Priority < 0 {
+			return scheduleInput{}, fmt.Errorf("estimate_minutes and priority must not be negative")
+		}
+	}
+	return payload, nil
+}
+
+// resolveDay returns midnight of the requested day in now's location.
+func resolveDay(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, nil
+	}
+	t, _, err := nldate.Parse(value, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or an expression like \"tomorrow\"", value)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location()), nil
+}
+
+func atClock(day time.Time, value, fallback string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = fallback
+	}
+	if value == "24:00" {
+		return day.AddDate(0, 0, 1), nil
+	}
+	c, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("use HH:MM")
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), c.Hour(), c.Minute(), 0, 0, day.Location()), nil
+}
+
+// busyIntervals returns the timed events of day plus the windows the user's
+// availability keeps free.
+func busyIntervals(ctx context.Context, provider calendar.CalendarProvider, avail profile.Availability, day time.Time) ([]scheduler.Interval, error) {
+	events, err := provider.ListEvents(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	var busy []scheduler.Interval
+	for _, e := range events {
+		if e.AllDay || e.Status == "cancelled" {
+			continue
+		}
+		busy = append(busy, scheduler.Interval{Start: e.Start, End: e.End, Title: e.Summary})
+	}
+	for _, w := range avail.Blocked(day, false) {
+		busy = append(busy, scheduler.Interval{Start: w.Start, End: w.End, Title: w.Label})
+	}
+	return busy, nil
+}
+
+func formatPlan(mode scheduler.Mode, plan scheduler.Plan, loc *time.Location) string {
+	var b strings.Builder
+	if len(plan.Blocks) == 0 {
+		b.WriteString("No task fits into the free time.\n")
+	} else {
+		b.WriteString(fmt.Sprintf("Proposed %s plan (%d blocks):\n", mode, len(plan.Blocks)))
+	}
+	for _, bl := range plan.Blocks {
+		start, end := bl.Start.In(loc), bl.End.In(loc)
+		line := fmt.Sprintf("- %s–%s %s (start_time: %s, end_time: %s",
+			start.Format("15:04"), end.Format("15:04"), bl.Title,
+			start.Format(time.RFC3339), end.Format(time.RFC3339))
+		if !strings.HasPrefix(bl.TaskID, "input-") {
+			line += ", task id: " + bl.TaskID
+		}
+		b.WriteString(line + ")\n")
+	}
+	if len(plan.Unscheduled) > 0 {
+		b.WriteString("Not scheduled:\n")
+		for _, u := range plan.Unscheduled {
+			b.WriteString(fmt.Sprintf("- %s (%s)\n", u.Task.Title, u.Reason))
+		}
+	}
+	return b.String()
+}
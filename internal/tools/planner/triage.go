@@ -0,0 +1,404 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"groundhog/internal/changes"
+	"groundhog/internal/profile"
+	"groundhog/internal/scheduler"
+	"groundhog/internal/tools/calendar"
+	"groundhog/internal/tools/tasks"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// Triage actions.
+const (
+	actionReschedule = "reschedule"
+	actionTomorrow   = "tomorrow"
+	actionDrop       = "drop"
+)
+
+const (
+	defaultStaleDays = 14
+	defaultSlotDays  = 5
+)
+
+// TriageTasks finds overdue and stale tasks and proposes, for each one, to
+// reschedule it into a free slot, push it to tomorrow or drop it. The
+// decisions are proposed as changes so the user can apply them in one go.
+type TriageTasks struct {
+	provider calendar.CalendarProvider
+	tasks    *tasks.ListTasks
+	apply    *applyTriage
+}
+
+var _ tools.Tool = &TriageTasks{}
+
+// NewTriageTasks returns the tool. addEvent, updateTask and deleteTask carry
+// out the decisions once the user applies them; pass the unwrapped tools.
+// Without a calendar, provider and addEvent are nil and tasks are pushed to
+// tomorrow instead of rescheduled.
+func NewTriageTasks(provider calendar.CalendarProvider, taskLister *tasks.ListTasks, addEvent, updateTask, deleteTask tools.Tool) *TriageTasks {
+	return &TriageTasks{
+		provider: provider,
+		tasks:    taskLister,
+		apply: &applyTriage{
+			addEvent:   addEvent,
+			updateTask: updateTask,
+			deleteTask: deleteTask,
+		},
+	}
+}
+
+func (t *TriageTasks) Name() string {
+	return "tasks_triage"
+}
+
+func (t *TriageTasks) Description() string {
+	return `Find overdue and stale open tasks and propose for each one: reschedule into a concrete free calendar slot, push to tomorrow, or drop. The decisions are proposed as changes that the user applies or rejects, one by one or all at once.
+
+Input is an optional stringified JSON object like:
+{"task_list_id": "@default", "stale_days": 14, "overrides": {"abc123": "drop"}}
+
+Fields:
+- task_list_id (string, optional): Task list id or name; omit for @default.
+- stale_days (integer, optional): tasks untouched for this many days count as stale (default 14).
+Reschedule needs a calendar; without one those tasks are pushed to tomorrow.
+- overrides (object, optional): task id → reschedule, tomorrow or drop, to change a decision the user disagreed with.`
+}
+
+func (t *TriageTasks) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+			"stale_days": map[string]interface{}{
+				"type":        "integer",
+				"description": "Days without changes after which a task is stale (default 14).",
+			},
+			"overrides": map[string]interface{}{
+				"type":                 "object",
+				"description":          "Task id → reschedule, tomorrow or drop.",
+				"additionalProperties": map[string]interface{}{"type": "string", "enum": []string{actionReschedule, actionTomorrow, actionDrop}},
+			},
+		},
+		"required": []string{},
+	}
+}
+
+func (t *TriageTasks) Call(ctx context.Context, input string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseTriageInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	open, listTitle, err := t.tasks.Fetch(ctx, tasks.TaskQuery{
+		TaskListID: payload.TaskListID,
+		Status:     "needsAction",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	userProfile := profile.FromContext(ctx)
+	loc := userProfile.Location()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	staleBefore := now.AddDate(0, 0, -payload.StaleDays)
+
+	var decisions []triageDecision
+	for _, task := range open {
+		if task.Status != "needsAction" {
+			continue
+		}
+		overdue := !task.Due.IsZero() && task.Due.Before(today)
+		stale := !task.Updated.IsZero() && task.Updated.Before(staleBefore)
+		if !overdue && !stale {
+			continue
+		}
+		d := triageDecision{
+			TaskID:          task.ID,
+			TaskListID:      task.ListID,
+			Title:           task.Title,
+			Action:          decide(task, now, payload.StaleDays),
+			EstimateMinutes: task.Meta.EstimateMinutes,
+			Priority:        task.Meta.Priority,
+		}
+		if overdue {
+			d.Reason = fmt.Sprintf("overdue since %s", task.Due.UTC().Format(time.DateOnly))
+		} else {
+			d.Reason = fmt.Sprintf("untouched since %s", task.Updated.In(loc).Format(time.DateOnly))
+		}
+		if action, ok := payload.Overrides[task.ID]; ok {
+			d.Action = action
+		}
+		decisions = append(decisions, d)
+	}
+	if len(decisions) == 0 {
+		return fmt.Sprintf("No overdue or stale tasks in \"%s\".", listTitle), nil
+	}
+
+	if err := t.findSlots(ctx, userProfile.Availability, now, decisions); err != nil {
+		return "", err
+	}
+	tomorrow := today.AddDate(0, 0, 1).Format(time.DateOnly)
+	for i := range decisions {
+		if decisions[i].Action == actionTomorrow {
+			decisions[i].Due = tomorrow
+		}
+	}
+
+	set := changes.FromContext(ctx)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Triage of \"%s\" (%d tasks):\n", listTitle, len(decisions))
+	for _, d := range decisions {
+		summary := d.summary(loc)
+		if set == nil {
+			fmt.Fprintf(&b, "- %s (%s)\n", summary, d.Reason)
+			continue
+		}
+		in, err := json.Marshal(d)
+		if err != nil {
+			return "", err
+		}
+		c := set.Add(t.apply, summary, string(in))
+		fmt.Fprintf(&b, "- change %s: %s (%s)\n", c.ID, summary, d.Reason)
+	}
+	if set == nil {
+		b.WriteString("Nothing has been changed. Carry out the decisions the user agrees with using the tasks and calendar tools.")
+	} else {
+		b.WriteString("Nothing has been changed yet. The user reviews the proposed changes and can apply them all at once; do not repeat this call.")
+	}
+	return b.String(), nil
+}
+
+// decide picks the default action: long-forgotten tasks without a high
+// priority are dropped, important or estimated work gets a slot, and the
+// rest moves to tomorrow.
+func decide(task tasks.Task, now time.Time, staleDays int) string {
+	important := task.Meta.Priority == 1 || task.Meta.Priority == 2
+	if !important && !task.Updated.IsZero() && task.Updated.Before(now.AddDate(0, 0, -2*staleDays)) {
+		return actionDrop
+	}
+	if important || task.Meta.EstimateMinutes > 0 {
+		return actionReschedule
+	}
+	return actionTomorrow
+}
+
+// findSlots places the tasks to reschedule into the free time of the next
+// days. Tasks that don't fit are pushed to tomorrow instead.
+func (t *TriageTasks) findSlots(ctx context.Context, avail profile.Availability, now time.Time, decisions []triageDecision) error {
+	if t.provider == nil {
+		for i := range decisions {
+			if decisions[i].Action == actionReschedule {
+				decisions[i].Action = actionTomorrow
+				decisions[i].Reason += "; no calendar to reschedule into"
+			}
+		}
+		return nil
+	}
+	pending := make(map[string]int)
+	var todo []scheduler.Task
+	for i, d := range decisions {
+		if d.Action != actionReschedule {
+			continue
+		}
+		pending[d.TaskID] = i
+		todo = append(todo, scheduler.Task{
+			ID:       d.TaskID,
+			Title:    d.Title,
+			Estimate: time.Duration(d.EstimateMinutes) * time.Minute,
+			Priority: d.Priority,
+		})
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for offset := 0; offset < defaultSlotDays && len(todo) > 0; offset++ {
+		day := today.AddDate(0, 0, offset)
+		start, end := day.Add(9*time.Hour), day.Add(17*time.Hour)
+		if len(avail.WorkingHours) > 0 {
+			w, ok := avail.Working(day)
+			if !ok {
+				continue
+			}
+			start, end = w.Start, w.End
+		}
+		if start.Before(now) {
+			start = now
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		busy, err := busyIntervals(ctx, t.provider, avail, day)
+		if err != nil {
+			return err
+		}
+		plan := scheduler.Schedule(scheduler.Request{
+			Mode:    scheduler.Flexible,
+			Working: []scheduler.Interval{{Start: start, End: end}},
+			Busy:    busy,
+			Tasks:   todo,
+		})
+		for _, bl := range plan.Blocks {
+			d := &decisions[pending[bl.TaskID]]
+			d.Start, d.End = bl.Start.Format(time.RFC3339), bl.End.Format(time.RFC3339)
+			d.Due = day.Format(time.DateOnly)
+		}
+		todo = todo[:0]
+		for _, u := range plan.Unscheduled {
+			todo = append(todo, u.Task)
+		}
+	}
+	for _, task := range todo {
+		d := &decisions[pending[task.ID]]
+		d.Action = actionTomorrow
+		d.Reason += fmt.Sprintf("; no free slot in the next %d days", defaultSlotDays)
+	}
+	return nil
+}
+
+type triageInput struct {
+	TaskListID string            `json:"task_list_id"`
+	StaleDays  int               `json:"stale_days"`
+	Overrides  map[string]string `json:"overrides"`
+}
+
+func parseTriageInput(raw string) (triageInput, error) {
+	var payload triageInput
+	if trimmed := strings.TrimSpace(raw); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+			return triageInput{}, fmt.Errorf("invalid triage payload; expected a JSON object: %w", err)
+		}
+	}
+	if payload.StaleDays < 0 {
+		return triageInput{}, fmt.Errorf("stale_days must not be negative")
+	}
+	if payload.StaleDays == 0 {
+		payload.StaleDays = defaultStaleDays
+	}
+	for id, action := range payload.Overrides {
+		switch action {
+		case actionReschedule, actionTomorrow, actionDrop:
+		default:
+			return triageInput{}, fmt.Errorf("override for %s must be reschedule, tomorrow or drop", id)
+		}
+	}
+	return payload, nil
+}
+
+// triageDecision is what happens to one task. It is also the input of the
+// proposed change that carries it out.
+type triageDecision struct {
+	TaskID     string `json:"task_id"`
+	TaskListID string `json:"task_list_id"`
+	Title      string `json:"title"`
+	Action     string `json:"action"`
+	// Due is the new due date for reschedule and tomorrow.
+	Due string `json:"due,omitempty"`
+	// Start and End are the calendar slot for reschedule.
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+
+	Reason          string `json:"-"`
+	EstimateMinutes int    `json:"-"`
+	Priority        int    `json:"-"`
+}
+
+func (d triageDecision) summary(loc *time.Location) string {
+	switch d.Action {
+	case actionReschedule:
+		start, _ := time.Parse(time.RFC3339, d.Start)
+		end, _ := time.Parse(time.RFC3339, d.End)
+		return fmt.Sprintf("Reschedule \"%s\" to %s–%s", d.Title, start.In(loc).Format("Mon 02 Jan 15:04"), end.In(loc).Format("15:04"))
+	case actionTomorrow:
+		return fmt.Sprintf("Push \"%s\" to tomorrow", d.Title)
+	default:
+		return fmt.Sprintf("Drop \"%s\"", d.Title)
+	}
+}
+
+// applyTriage carries out one triage decision when the user applies it. It
+// is not offered to the agent.
+type applyTriage struct {
+	addEvent   tools.Tool
+	updateTask tools.Tool
+	deleteTask tools.Tool
+}
+
+var _ tools.Tool = &applyTriage{}
+
+func (a *applyTriage) Name() string {
+	return "tasks_triage"
+}
+
+func (a *applyTriage) Description() string {
+	return "Apply a triage decision."
+}
+
+func (a *applyTriage) Call(ctx context.Context, input string) (string, error) {
+	var d triageDecision
+	if err := json.Unmarshal([]byte(input), &d); err != nil {
+		return "", fmt.Errorf("invalid triage decision: %w", err)
+	}
+
+	switch d.Action {
+	case actionDrop:
+		return callJSON(ctx, a.deleteTask, map[string]any{"task_id": d.TaskID, "task_list_id": d.TaskListID})
+	case actionTomorrow:
+		return callJSON(ctx, a.updateTask, map[string]any{"task_id": d.TaskID, "task_list_id": d.TaskListID, "due": d.Due})
+	case actionReschedule:
+		block := map[string]any{
+			"summary":     d.Title,
+			"start_time":  d.Start,
+			"end_time":    d.End,
+			"focus_block": true,
+		}
+		// AddEvent answers an availability conflict instead of failing, so
+		// the slot is checked before anything changes.
+		if describer, ok := a.addEvent.(changes.Describer); ok {
+			raw, err := json.Marshal(block)
+			if err != nil {
+				return "", err
+			}
+			if _, err := describer.Describe(ctx, string(raw)); err != nil {
+				return "", fmt.Errorf("not rescheduled \"%s\": %w", d.Title, err)
+			}
+		}
+		updated, err := callJSON(ctx, a.updateTask, map[string]any{"task_id": d.TaskID, "task_list_id": d.TaskListID, "due": d.Due})
+		if err != nil {
+			return "", err
+		}
+		if a.addEvent == nil {
+			return updated, nil
+		}
+		added, err := callJSON(ctx, a.addEvent, block)
+		if err != nil {
+			return "", fmt.Errorf("%s but the time block failed: %w", updated, err)
+		}
+		return updated + "\n" + added, nil
+	default:
+		return "", fmt.Errorf("unknown triage action %q", d.Action)
+	}
+}
+
+func callJSON(ctx context.Context, tool tools.Tool, input map[string]any) (string, error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	return tool.Call(ctx, string(raw))
+}
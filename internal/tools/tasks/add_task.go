@@ -7,21 +7,23 @@ import (
 	"strings"
 	"time"
 
-	gtasks "google.golang.org/api/tasks/v1"
+	"groundhog/internal/nldate"
+	"groundhog/internal/profile"
+	"groundhog/internal/taskmeta"
 
 	"github.com/tmc/langchaingo/tools"
 )
 
-// AddTask creates a new task in Google Tasks.
+// AddTask creates a new task.
 type AddTask struct {
-	credFile string
+	provider TaskProvider
 }
 
 var _ tools.Tool = &AddTask{}
 
-func NewAddTask(credFile string) *AddTask {
+func NewAddTask(provider TaskProvider) *AddTask {
 	return &AddTask{
-		credFile: credFile,
+		provider: provider,
 	}
 }
 
@@ -30,7 +32,7 @@ func (a *AddTask) Name() string {
 }
 
 func (a *AddTask) Description() string {
-	return `Add a task to Google Tasks.
+	return `Add a task to the user's task list.
 
 Input must be a stringified JSON object like:
 {
@@ -39,16 +41,26 @@ Input must be a stringified JSON object like:
   "start_time": "2025-12-10T09:00:00-05:00",
   "due": "2025-12-10",
   "status": "needsAction",
-  "task_list_id": "@default"
+  "estimate_minutes": 30,
+  "priority": 2,
+  "task_list_id": "@default",
+  "parent": "",
+  "previous": ""
 }
 
 Fields:
 - title (string, required): task title.
 - notes (string, optional): additional details.
-- start_time (string, optional): RFC3339 timestamp or YYYY-MM-DD. Stored in the task and echoed in notes.
-- due (string, optional): RFC3339 timestamp or YYYY-MM-DD.
+- start_time (string, optional): RFC3339 timestamp or YYYY-MM-DD. Stored in the task's metadata.
+- due (string, optional): RFC3339 timestamp, YYYY-MM-DD, or a relative expression like "next Friday".
 - status (string, optional): needsAction or completed. Defaults to needsAction.
-- task_list_id (string, optional): Task list id; omit for @default.`
+- estimate_minutes (integer, optional): how long the task takes; used by schedule_tasks.
+- priority (integer, optional): 1 is the most important.
+- energy (string, optional): low, medium or high effort.
+- goal (string, optional): the larger goal this task serves.
+- task_list_id (string, optional): Task list id or name; omit for @default.
+- parent (string, optional): id of a task to create this one as its subtask.
+- previous (string, optional): id of the sibling to place this task after; omit to put it first.`
 }
 
 func (a *AddTask) Parameters() map[string]interface{} {
@@ -76,9 +88,34 @@ func (a *AddTask) Parameters() map[string]interface{} {
 				"description": "Task status: needsAction or completed.",
 				"enum":        []string{"needsAction", "completed"},
 			},
+			"estimate_minutes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Estimated minutes of work.",
+			},
+			"priority": map[string]interface{}{
+				"type":        "integer",
+				"description": "Priority; 1 is the most important.",
+			},
+			"energy": map[string]interface{}{
+				"type":        "string",
+				"description": "Effort the task demands.",
+				"enum":        []string{"low", "medium", "high"},
+			},
+			"goal": map[string]interface{}{
+				"type":        "string",
+				"description": "The larger goal this task serves.",
+			},
 			"task_list_id": map[string]interface{}{
 				"type":        "string",
-				"description": "Task list id; omit to use the default list (@default).",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+			"parent": map[string]interface{}{
+				"type":        "string",
+				"description": "Id of the parent task when creating a subtask.",
+			},
+			"previous": map[string]interface{}{
+				"type":        "string",
+				"description": "Id of the sibling task to place this one after.",
 			},
 		},
 		"required": []string{"title"},
@@ -96,62 +133,88 @@ func (a *AddTask) Call(ctx context.Context, input string) (string, error) {
 		return "", err
 	}
 
-	srv, err := newTasksService(ctx, a.credFile)
-	if err != nil {
-		return "", err
-	}
-
-	taskListID := strings.TrimSpace(payload.TaskListID)
-	if taskListID == "" {
-		taskListID = "@default"
+	loc := profile.Location(ctx)
+	task := Task{
+		ListID: payload.TaskListID,
+		Title:  strings.TrimSpace(payload.Title),
+		Notes:  payload.Notes,
+		Meta:   payload.meta(),
+		Status: "needsAction",
+		Parent: payload.Parent,
 	}
-
-	task := &gtasks.Task{
-		Title: strings.TrimSpace(payload.Title),
-		Notes: strings.TrimSpace(payload.Notes),
+	if payload.Status != "" {
+		task.Status = payload.Status
 	}
 
 	var startNormalized string
 	if payload.StartTime != "" {
-		startNormalized, err = normalizeDue(payload.StartTime)
+		startNormalized, err = normalizeDue(payload.StartTime, loc)
 		if err != nil {
 			return "", fmt.Errorf("invalid start_time: %w", err)
 		}
 	}
 
-	if payload.Status != "" {
-		task.Status = payload.Status
-	}
-
 	if payload.Due != "" {
-		normalizedDue, err := normalizeDue(payload.Due)
+		normalizedDue, err := normalizeDue(payload.Due, loc)
 		if err != nil {
 			return "", fmt.Errorf("invalid due: %w", err)
 		}
-		task.Due = normalizedDue
+		task.Due = parseTaskTime(normalizedDue)
 	} else if startNormalized != "" {
-		// Google Tasks has no start time; store start in Due for ordering.
-		task.Due = startNormalized
+		// Tasks have no start time; store start in Due for ordering.
+		task.Due = parseTaskTime(startNormalized)
 	}
+	task.Meta.Start = startNormalized
 
-	if startNormalized != "" {
-		if task.Notes != "" {
-			task.Notes += "\n"
-		}
-		task.Notes += fmt.Sprintf("Start: %s", startNormalized)
+	created, err := a.provider.InsertTask(ctx, task, payload.Previous)
+	if err != nil {
+		return "", err
 	}
 
-	created, err := srv.Tasks.Insert(taskListID, task).Context(ctx).Do()
-	if err != nil {
-		return "", fmt.Errorf("unable to create task: %w", err)
+	if created.Parent != "" {
+		return fmt.Sprintf("Created subtask \"%s\" (status: %s, id: %s, parent: %s)", created.Title, created.Status, created.ID, created.Parent), nil
 	}
+	return fmt.Sprintf("Created task \"%s\" (status: %s, id: %s)", created.Title, created.Status, created.ID), nil
+}
 
-	status := strings.TrimSpace(created.Status)
-	if status == "" {
-		status = "needsAction"
+// Describe validates input and summarises the task it would create.
+func (a *AddTask) Describe(ctx context.Context, input string) (string, error) {
+	payload, err := parseAddTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+	desc := fmt.Sprintf("Create task \"%s\"", strings.TrimSpace(payload.Title))
+	if payload.Parent != "" {
+		desc = fmt.Sprintf("Create subtask \"%s\"", strings.TrimSpace(payload.Title))
+	}
+	if payload.Due != "" {
+		due, err := normalizeDue(payload.Due, profile.Location(ensureContext(ctx)))
+		if err != nil {
+			return "", fmt.Errorf("invalid due: %w", err)
+		}
+		desc += fmt.Sprintf(" due %s", due)
+	}
+	if meta := payload.meta().Summary(); meta != "" {
+		desc += fmt.Sprintf(" (%s)", meta)
 	}
+	return desc, nil
+}
 
-	return fmt.Sprintf("Created task \"%s\" (status: %s, id: %s)", created.Title, status, created.Id), nil
+// Resolve makes relative due and start times absolute.
+func (a *AddTask) Resolve(ctx context.Context, input string) (string, error) {
+	payload, err := parseAddTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+	loc := profile.Location(ensureContext(ctx))
+	if payload.Due, err = normalizeDue(payload.Due, loc); err != nil {
+		return "", fmt.Errorf("invalid due: %w", err)
+	}
+	if payload.StartTime, err = normalizeDue(payload.StartTime, loc); err != nil {
+		return "", fmt.Errorf("invalid start_time: %w", err)
+	}
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
 }
 
 type addTaskInput struct {
@@ -161,6 +224,22 @@ type addTaskInput struct {
 	Due        string `json:"due"`
 	Status     string `json:"status"`
 	TaskListID string `json:"task_list_id"`
+	Parent     string `json:"parent"`
+	Previous   string `json:"previous"`
+
+	EstimateMinutes int    `json:"estimate_minutes"`
+	Priority        int    `json:"priority"`
+	Energy          string `json:"energy"`
+	Goal            string `json:"goal"`
+}
+
+func (in addTaskInput) meta() taskmeta.Meta {
+	return taskmeta.Meta{
+		EstimateMinutes: in.EstimateMinutes,
+		Priority:        in.Priority,
+		Energy:          in.Energy,
+		Goal:            strings.TrimSpace(in.Goal),
+	}
 }
 
 func parseAddTaskInput(raw string) (addTaskInput, error) {
@@ -177,6 +256,16 @@ func parseAddTaskInput(raw string) (addTaskInput, error) {
 	if strings.TrimSpace(payload.Title) == "" {
 		return addTaskInput{}, fmt.Errorf("title is required to create a task")
 	}
+	if payload.EstimateMinutes < 0 || payload.Priority < 0 {
+		return addTaskInput{}, fmt.Errorf("estimate_minutes and priority must not be negative")
+	}
+	energy, err := taskmeta.ParseEnergy(payload.Energy)
+	if err != nil {
+		return addTaskInput{}, err
+	}
+	payload.Energy = energy
+	payload.Parent = strings.TrimSpace(payload.Parent)
+	payload.Previous = strings.TrimSpace(payload.Previous)
 
 	if payload.Status != "" && payload.Status != "needsAction" && payload.Status != "completed" {
 		return addTaskInput{}, fmt.Errorf("status must be needsAction or completed when provided")
@@ -185,7 +274,8 @@ func parseAddTaskInput(raw string) (addTaskInput, error) {
 	return payload, nil
 }
 
-func normalizeDue(input string) (string, error) {
+// normalizeDue resolves relative expressions against the current time in loc.
+func normalizeDue(input string, loc *time.Location) (string, error) {
 	value := strings.TrimSpace(input)
 	if value == "" {
 		return "", nil
@@ -199,5 +289,12 @@ func normalizeDue(input string) (string, error) {
 		return t.Format(time.RFC3339), nil
 	}
 
-	return "", fmt.Errorf("could not parse due date %q; use RFC3339 or YYYY-MM-DD", input)
+	if t, dateOnly, err := nldate.Parse(value, time.Now().In(loc)); err == nil {
+		if dateOnly {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Format(time.RFC3339), nil
+		}
+		return t.Format(time.RFC3339), nil
+	}
+
+	return "", fmt.Errorf("could not parse due date %q; use RFC3339, YYYY-MM-DD, or an expression like \"tomorrow\" or \"next Friday\"", input)
 }
@@ -0,0 +1,228 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gtasks "google.golang.org/api/tasks/v1"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// DeleteTask removes a task and its subtasks.
+type DeleteTask struct {
+	provider TaskProvider
+}
+
+var _ tools.Tool = &DeleteTask{}
+
+func NewDeleteTask(provider TaskProvider) *DeleteTask {
+	return &DeleteTask{
+		provider: provider,
+	}
+}
+
+func (d *DeleteTask) Name() string {
+	return "tasks_delete"
+}
+
+func (d *DeleteTask) Description() string {
+	return `Delete a task and its subtasks, e.g. one the user decided to skip. The user confirms every deletion.
+
+Input is a stringified JSON object like {"task_id": "abc123", "task_list_id": "@default"}.`
+}
+
+func (d *DeleteTask) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"task_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the task to delete (required).",
+			},
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+		},
+		"required": []string{"task_id"},
+	}
+}
+
+func (d *DeleteTask) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseDeleteTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := d.provider.GetTask(ctx, payload.TaskListID, payload.TaskID)
+	if err != nil {
+		return "", err
+	}
+	if err := d.provider.DeleteTask(ctx, payload.TaskListID, payload.TaskID); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Deleted task \"%s\".", existing.Title), nil
+}
+
+// Describe checks that the task exists and names it.
+func (d *DeleteTask) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseDeleteTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+	existing, err := d.provider.GetTask(ctx, payload.TaskListID, payload.TaskID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Delete task \"%s\"", existing.Title), nil
+}
+
+type deleteTaskInput struct {
+	TaskID     string `json:"task_id"`
+	TaskListID string `json:"task_list_id,omitempty"`
+}
+
+func parseDeleteTaskInput(raw string) (deleteTaskInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return deleteTaskInput{}, fmt.Errorf("provide the task as a JSON object in the tool input")
+	}
+
+	var payload deleteTaskInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return deleteTaskInput{}, fmt.Errorf("invalid delete task payload; expected a JSON object: %w", err)
+	}
+
+	payload.TaskID = strings.TrimSpace(payload.TaskID)
+	if payload.TaskID == "" {
+		return deleteTaskInput{}, fmt.Errorf("task_id is required to delete a task")
+	}
+	return payload, nil
+}
+
+// ClearCompleted hides all completed tasks of a list, which is how Google
+// Tasks cleans up finished work.
+type ClearCompleted struct {
+	credFile string
+}
+
+var _ tools.Tool = &ClearCompleted{}
+
+func NewClearCompleted(credFile string) *ClearCompleted {
+	return &ClearCompleted{
+		credFile: credFile,
+	}
+}
+
+func (c *ClearCompleted) Name() string {
+	return "tasks_clear_completed"
+}
+
+func (c *ClearCompleted) Description() string {
+	return `Clear all completed tasks from a Google Tasks list. They disappear from the list and this can't be undone. The user confirms before anything is cleared.
+
+Input is an optional stringified JSON object like {"task_list_id": "@default"}.`
+}
+
+func (c *ClearCompleted) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+		},
+		"required": []string{},
+	}
+}
+
+func (c *ClearCompleted) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	listValue, err := parseClearCompletedInput(input)
+	if err != nil {
+		return "", err
+	}
+	listID, title, count, err := c.completed(ctx, listValue)
+	if err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return fmt.Sprintf("No completed tasks in \"%s\".", title), nil
+	}
+
+	srv, err := newTasksService(ctx, c.credFile)
+	if err != nil {
+		return "", err
+	}
+	if err := srv.Tasks.Clear(listID).Context(ctx).Do(); err != nil {
+		return "", fmt.Errorf("unable to clear completed tasks: %w", err)
+	}
+	return fmt.Sprintf("Cleared %d completed tasks from \"%s\".", count, title), nil
+}
+
+// Describe counts the tasks that would be cleared.
+func (c *ClearCompleted) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	listValue, err := parseClearCompletedInput(input)
+	if err != nil {
+		return "", err
+	}
+	_, title, count, err := c.completed(ctx, listValue)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Clear %d completed tasks from \"%s\"", count, title), nil
+}
+
+// completed resolves the list and counts the completed tasks still visible
+// in it.
+func (c *ClearCompleted) completed(ctx context.Context, list string) (string, string, int, error) {
+	srv, err := newTasksService(ctx, c.credFile)
+	if err != nil {
+		return "", "", 0, err
+	}
+	listID, title, err := resolveTaskList(ctx, srv, list)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	count := 0
+	err = srv.Tasks.List(listID).ShowCompleted(true).ShowHidden(false).MaxResults(100).Pages(ctx, func(page *gtasks.Tasks) error {
+		for _, item := range page.Items {
+			if item.Status == "completed" {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", "", 0, fmt.Errorf("unable to retrieve tasks: %w", err)
+	}
+	return listID, title, count, nil
+}
+
+func parseClearCompletedInput(raw string) (string, error) {
+	var payload struct {
+		TaskListID string `json:"task_list_id"`
+	}
+	if trimmed := strings.TrimSpace(raw); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+			return "", fmt.Errorf("invalid clear completed payload; expected a JSON object: %w", err)
+		}
+	}
+	return strings.TrimSpace(payload.TaskListID), nil
+}
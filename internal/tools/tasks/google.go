@@ -0,0 +1,279 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"groundhog/internal/taskmeta"
+
+	"google.golang.org/api/googleapi"
+	gtasks "google.golang.org/api/tasks/v1"
+)
+
+// GoogleTaskProvider stores tasks in Google Tasks. Metadata that Google has
+// no field for lives in a block at the end of the notes.
+type GoogleTaskProvider struct {
+	credFile string
+}
+
+var _ TaskProvider = &GoogleTaskProvider{}
+
+func NewGoogleTaskProvider(credFile string) *GoogleTaskProvider {
+	return &GoogleTaskProvider{
+		credFile: credFile,
+	}
+}
+
+func (g *GoogleTaskProvider) ListTasks(ctx context.Context, q TaskQuery) ([]Task, string, error) {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return nil, "", err
+	}
+
+	taskListID, listTitle, err := resolveTaskList(ctx, srv, q.TaskListID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	listCall := srv.Tasks.List(taskListID).
+		ShowCompleted(q.completed()).
+		ShowHidden(q.completed()).
+		MaxResults(100)
+	if !q.DueMin.IsZero() {
+		listCall = listCall.DueMin(q.DueMin.Format(time.RFC3339))
+	}
+	if !q.DueMax.IsZero() {
+		listCall = listCall.DueMax(q.DueMax.Format(time.RFC3339))
+	}
+	if !q.CompletedMin.IsZero() {
+		listCall = listCall.CompletedMin(q.CompletedMin.Format(time.RFC3339))
+	}
+	if !q.CompletedMax.IsZero() {
+		listCall = listCall.CompletedMax(q.CompletedMax.Format(time.RFC3339))
+	}
+
+	var res []Task
+	err = listCall.Pages(ctx, func(page *gtasks.Tasks) error {
+		for _, item := range page.Items {
+			if t := fromGoogleTask(taskListID, item); q.matches(t) {
+				res = append(res, t)
+			}
+			if q.full(len(res)) {
+				return errEnoughTasks
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnoughTasks) {
+		return nil, "", fmt.Errorf("unable to retrieve tasks: %w", err)
+	}
+	return res, listTitle, nil
+}
+
+// errEnoughTasks stops paging once MaxResults tasks were collected.
+var errEnoughTasks = errors.New("enough tasks")
+
+func (g *GoogleTaskProvider) InsertTask(ctx context.Context, task Task, previous string) (Task, error) {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return Task{}, err
+	}
+
+	taskListID, _, err := resolveTaskList(ctx, srv, task.ListID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	item := &gtasks.Task{
+		Title:  task.Title,
+		Notes:  taskmeta.Format(task.Notes, task.Meta),
+		Status: task.Status,
+	}
+	if !task.Due.IsZero() {
+		item.Due = task.Due.Format(time.RFC3339)
+	}
+
+	call := srv.Tasks.Insert(taskListID, item).Context(ctx)
+	if task.Parent != "" {
+		call = call.Parent(task.Parent)
+	}
+	if previous != "" {
+		call = call.Previous(previous)
+	}
+	created, err := call.Do()
+	if err != nil {
+		return Task{}, fmt.Errorf("unable to create task: %w", err)
+	}
+	recordTaskChange(ctx, taskListID, nil, created)
+	return fromGoogleTask(taskListID, created), nil
+}
+
+func (g *GoogleTaskProvider) GetTask(ctx context.Context, listID, id string) (Task, error) {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return Task{}, err
+	}
+	taskListID, _, err := resolveTaskList(ctx, srv, listID)
+	if err != nil {
+		return Task{}, err
+	}
+	item, err := getGoogleTask(ctx, srv, taskListID, id)
+	if err != nil {
+		return Task{}, err
+	}
+	return fromGoogleTask(taskListID, item), nil
+}
+
+func (g *GoogleTaskProvider) UpdateTask(ctx context.Context, task Task) (Task, error) {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return Task{}, err
+	}
+	taskListID, _, err := resolveTaskList(ctx, srv, task.ListID)
+	if err != nil {
+		return Task{}, err
+	}
+	existing, err := getGoogleTask(ctx, srv, taskListID, task.ID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	// Every field is sent so cleared values are removed too.
+	patch := &gtasks.Task{
+		Title:           task.Title,
+		Notes:           taskmeta.Format(task.Notes, task.Meta),
+		Status:          task.Status,
+		ForceSendFields: []string{"Title", "Notes", "Status"},
+	}
+	if task.Due.IsZero() {
+		patch.NullFields = append(patch.NullFields, "Due")
+	} else {
+		patch.Due = task.Due.Format(time.RFC3339)
+	}
+	if task.Status != "completed" {
+		// Reopening only sticks once the completion time is gone.
+		patch.NullFields = append(patch.NullFields, "Completed")
+	}
+	saved, err := srv.Tasks.Patch(taskListID, task.ID, patch).Context(ctx).Do()
+	if err != nil {
+		return Task{}, fmt.Errorf("unable to update task: %w", err)
+	}
+	recordTaskChange(ctx, taskListID, existing, saved)
+	return fromGoogleTask(taskListID, saved), nil
+}
+
+func (g *GoogleTaskProvider) DeleteTask(ctx context.Context, listID, id string) error {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return err
+	}
+	taskListID, _, err := resolveTaskList(ctx, srv, listID)
+	if err != nil {
+		return err
+	}
+	existing, err := getGoogleTask(ctx, srv, taskListID, id)
+	if err != nil {
+		return err
+	}
+	if err := srv.Tasks.Delete(taskListID, id).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to delete task: %w", err)
+	}
+	recordTaskDelete(ctx, taskListID, existing)
+	return nil
+}
+
+func (g *GoogleTaskProvider) MoveTask(ctx context.Context, listID, id, parent, previous string) (Task, error) {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return Task{}, err
+	}
+	taskListID, _, err := resolveTaskList(ctx, srv, listID)
+	if err != nil {
+		return Task{}, err
+	}
+	existing, err := getGoogleTask(ctx, srv, taskListID, id)
+	if err != nil {
+		return Task{}, err
+	}
+	before, err := previousSibling(ctx, srv, taskListID, existing)
+	if err != nil {
+		return Task{}, err
+	}
+
+	call := srv.Tasks.Move(taskListID, id).Context(ctx)
+	if parent != "" {
+		call = call.Parent(parent)
+	}
+	if previous != "" {
+		call = call.Previous(previous)
+	}
+	moved, err := call.Do()
+	if err != nil {
+		return Task{}, fmt.Errorf("unable to move task: %w", err)
+	}
+	recordTaskMove(ctx, moved,
+		loggedMove{ListID: taskListID, Parent: existing.Parent, Previous: before},
+		loggedMove{ListID: taskListID, Parent: moved.Parent, Previous: previous})
+	return fromGoogleTask(taskListID, moved), nil
+}
+
+// getGoogleTask fetches a task, reporting missing and deleted tasks as
+// ErrTaskNotFound.
+func getGoogleTask(ctx context.Context, srv *gtasks.Service, listID, id string) (*gtasks.Task, error) {
+	item, err := srv.Tasks.Get(listID, id).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %q", ErrTaskNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch task %q: %w", id, err)
+	}
+	if item.Deleted {
+		return nil, fmt.Errorf("%w: %q", ErrTaskNotFound, id)
+	}
+	return item, nil
+}
+
+// previousSibling returns the id of the task placed right before task under
+// the same parent, or "" when it is the first.
+func previousSibling(ctx context.Context, srv *gtasks.Service, listID string, task *gtasks.Task) (string, error) {
+	var siblings []*gtasks.Task
+	err := srv.Tasks.List(listID).ShowCompleted(true).ShowHidden(true).MaxResults(100).Pages(ctx, func(page *gtasks.Tasks) error {
+		for _, item := range page.Items {
+			if item.Parent == task.Parent {
+				siblings = append(siblings, item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve tasks: %w", err)
+	}
+	sort.Slice(siblings, func(i, j int) bool { return siblings[i].Position < siblings[j].Position })
+
+	previous := ""
+	for _, s := range siblings {
+		if s.Id == task.Id {
+			return previous, nil
+		}
+		previous = s.Id
+	}
+	return "", nil
+}
+
+func (g *GoogleTaskProvider) CreateList(ctx context.Context, title string) (string, error) {
+	srv, err := newTasksService(ctx, g.credFile)
+	if err != nil {
+		return "", err
+	}
+	created, err := srv.Tasklists.Insert(&gtasks.TaskList{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to create task list: %w", err)
+	}
+	recordTaskListChange(ctx, nil, created)
+	return created.Id, nil
+}
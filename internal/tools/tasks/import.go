@@ -0,0 +1,690 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"groundhog/internal/nldate"
+	"groundhog/internal/profile"
+	"groundhog/internal/taskmeta"
+)
+
+// Formats ImportTasks reads.
+const (
+	FormatTodoistCSV  = "todoist-csv"
+	FormatTodoistJSON = "todoist-json"
+	FormatTaskwarrior = "taskwarrior"
+	FormatCSV         = "csv"
+)
+
+// ImportOptions controls ImportTasks.
+type ImportOptions struct {
+	// Format is one of the Format constants; empty detects it from the data.
+	Format string
+	// List receives the tasks without a project. Todoist CSV exports hold a
+	// single project without naming it, so pass its name here. Empty selects
+	// the default list.
+	List             string
+	IncludeCompleted bool
+	// DryRun reports what would be imported without creating anything.
+	DryRun bool
+}
+
+// ImportResult summarises an import. Tasks lists what was created, or what
+// would be in a dry run.
+type ImportResult struct {
+	Format   string         `json:"format"`
+	DryRun   bool           `json:"dry_run"`
+	Created  int            `json:"created"`
+	Skipped  int            `json:"skipped"`
+	Failed   int            `json:"failed"`
+	NewLists []string       `json:"new_lists,omitempty"`
+	Tasks    []ImportedTask `json:"tasks,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// ImportedTask is one task of an import in the result.
+type ImportedTask struct {
+	List            string `json:"list"`
+	Title           string `json:"title"`
+	Parent          string `json:"parent,omitempty"`
+	Due             string `json:"due,omitempty"`
+	Priority        int    `json:"priority,omitempty"`
+	EstimateMinutes int    `json:"estimate_minutes,omitempty"`
+	Status          string `json:"status"`
+	// Error is why the task couldn't be created.
+	Error string `json:"error,omitempty"`
+}
+
+// importItem is a task read from an export. Projects become list names and
+// key/parentKey link subtasks within the export.
+type importItem struct {
+	key       string
+	parentKey string
+	list      string
+	task      Task
+}
+
+// ImportTasks creates the tasks of a Todoist, Taskwarrior or CSV export
+// through provider. Projects map to task lists, which are created when
+// missing. Tasks whose title already exists in their list are skipped, and
+// completed tasks are skipped unless opts.IncludeCompleted is set.
+func ImportTasks(ctx context.Context, provider TaskProvider, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	ctx = ensureContext(ctx)
+	result := ImportResult{DryRun: opts.DryRun}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return result, fmt.Errorf("unable to read import: %w", err)
+	}
+	result.Format = opts.Format
+	if result.Format == "" {
+		result.Format = detectImportFormat(data)
+	}
+
+	var items []importItem
+	loc := profile.Location(ctx)
+	switch result.Format {
+	case FormatTodoistCSV:
+		items, result.Warnings, err = parseTodoistCSV(data, loc)
+	case FormatTodoistJSON:
+		items, result.Warnings, err = parseTodoistJSON(data, loc)
+	case FormatTaskwarrior:
+		items, result.Warnings, err = parseTaskwarrior(data, loc)
+	case FormatCSV:
+		items, result.Warnings, err = parsePlainCSV(data, loc)
+	default:
+		return result, fmt.Errorf("unknown import format %q; use %s, %s, %s or %s", result.Format, FormatTodoistCSV, FormatTodoistJSON, FormatTaskwarrior, FormatCSV)
+	}
+	if err != nil {
+		return result, err
+	}
+	items = parentsFirst(items)
+
+	// Existing titles per list, to skip tasks imported before and to hang
+	// subtasks under parents that already exist.
+	existing := make(map[string]map[string]string)
+	missing := make(map[string]bool)
+	ids := make(map[string]string)
+	titles := make(map[string]string)
+	var pending []importItem
+	for _, item := range items {
+		if item.list == "" {
+			item.list = opts.List
+		}
+		if item.task.Status == "completed" && !opts.IncludeCompleted {
+			result.Skipped++
+			continue
+		}
+
+		if _, ok := existing[item.list]; !ok && !missing[item.list] {
+			current, _, err := provider.ListTasks(ctx, TaskQuery{TaskListID: item.list, IncludeCompleted: true})
+			switch {
+			case errors.Is(err, ErrUnknownList):
+				missing[item.list] = true
+				result.NewLists = append(result.NewLists, item.list)
+			case err != nil:
+				return result, err
+			default:
+				existing[item.list] = make(map[string]string, len(current))
+				for _, t := range current {
+					existing[item.list][strings.ToLower(t.Title)] = t.ID
+				}
+			}
+		}
+		titles[item.key] = item.task.Title
+		if id, ok := existing[item.list][strings.ToLower(item.task.Title)]; ok {
+			ids[item.key] = id
+			result.Skipped++
+			result.Warnings = append(result.Warnings, fmt.Sprintf("\"%s\" already exists in its list; skipped", item.task.Title))
+			continue
+		}
+
+		pending = append(pending, item)
+		preview := ImportedTask{
+			List:            item.list,
+			Title:           item.task.Title,
+			Parent:          titles[item.parentKey],
+			Priority:        item.task.Meta.Priority,
+			EstimateMinutes: item.task.Meta.EstimateMinutes,
+			Status:          item.task.Status,
+		}
+		if preview.List == "" {
+			preview.List = defaultTaskList
+		}
+		if !item.task.Due.IsZero() {
+			preview.Due = item.task.Due.Format(time.DateOnly)
+		}
+		result.Tasks = append(result.Tasks, preview)
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	listIDs := make(map[string]string)
+	for _, name := range result.NewLists {
+		id, err := provider.CreateList(ctx, name)
+		if err != nil {
+			return result, err
+		}
+		listIDs[name] = id
+	}
+
+	// The last task created under each parent keeps the export's order. A
+	// task that fails doesn't stop the import, but its subtasks fail too
+	// rather than landing at the top level.
+	last := make(map[string]string)
+	failed := make(map[string]bool)
+	for i, item := range pending {
+		task := item.task
+		task.ListID = item.list
+		if id, ok := listIDs[item.list]; ok {
+			task.ListID = id
+		}
+		if failed[item.parentKey] {
+			failed[item.key] = true
+			result.Failed++
+			result.Tasks[i].Error = fmt.Sprintf("parent \"%s\" wasn't created", titles[item.parentKey])
+			continue
+		}
+		task.Parent = ids[item.parentKey]
+		sibling := task.ListID + "\x00" + task.Parent
+
+		created, err := provider.InsertTask(ctx, task, last[sibling])
+		if err != nil {
+			failed[item.key] = true
+			result.Failed++
+			result.Tasks[i].Error = err.Error()
+			continue
+		}
+		ids[item.key] = created.ID
+		last[sibling] = created.ID
+		result.Created++
+	}
+	return result, nil
+}
+
+func detectImportFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if isTaskwarrior(trimmed) {
+			return FormatTaskwarrior
+		}
+		return FormatTodoistJSON
+	}
+	header, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	header = bytes.ToUpper(header)
+	if bytes.Contains(header, []byte("TYPE")) && bytes.Contains(header, []byte("CONTENT")) {
+		return FormatTodoistCSV
+	}
+	return FormatCSV
+}
+
+// isTaskwarrior reports whether data holds Taskwarrior tasks, as an array or
+// one per line, judging by the first one: it has a uuid and a description.
+// Todoist exports may carry uuids too, but on nested objects.
+func isTaskwarrior(data []byte) bool {
+	first := data
+	if data[0] == '[' {
+		var tasks []json.RawMessage
+		if err := json.Unmarshal(data, &tasks); err != nil || len(tasks) == 0 {
+			return false
+		}
+		first = tasks[0]
+	} else {
+		line, _, _ := bytes.Cut(data, []byte("\n"))
+		first = bytes.TrimRight(bytes.TrimSpace(line), ",")
+	}
+	var task struct {
+		UUID        string `json:"uuid"`
+		Description string `json:"description"`
+	}
+	return json.Unmarshal(first, &task) == nil && task.UUID != "" && task.Description != ""
+}
+
+// parentsFirst orders items so every parent precedes its subtasks, keeping
+// the export's order otherwise. Parents missing from the export are dropped.
+func parentsFirst(items []importItem) []importItem {
+	byKey := make(map[string]importItem, len(items))
+	for _, item := range items {
+		byKey[item.key] = item
+	}
+	depth := func(item importItem) int {
+		d := 0
+		for seen := map[string]bool{}; item.parentKey != "" && !seen[item.key]; d++ {
+			seen[item.key] = true
+			parent, ok := byKey[item.parentKey]
+			if !ok {
+				break
+			}
+			item = parent
+		}
+		return d
+	}
+
+	res := make([]importItem, len(items))
+	copy(res, items)
+	for i := range res {
+		if _, ok := byKey[res[i].parentKey]; !ok {
+			res[i].parentKey = ""
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return depth(res[i]) < depth(res[j]) })
+	return res
+}
+
+// parseTodoistCSV reads a Todoist project template export: TYPE, CONTENT,
+// DESCRIPTION, PRIORITY (1 is p1), INDENT, DATE and, in newer exports,
+// DURATION and DURATION_UNIT. Notes rows are appended to the task above.
+func parseTodoistCSV(data []byte, loc *time.Location) ([]importItem, []string, error) {
+	rows, col, err := readCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := col["content"]; !ok {
+		return nil, nil, fmt.Errorf("not a Todoist CSV export: missing the CONTENT column")
+	}
+
+	var items []importItem
+	var warnings []string
+	var stack []string
+	for i, row := range rows {
+		get := func(name string) string { return field(row, col, name) }
+		switch strings.ToLower(get("type")) {
+		case "task":
+		case "note":
+			if len(items) > 0 {
+				last := &items[len(items)-1].task
+				last.Notes = strings.TrimSpace(last.Notes + "\n" + get("content"))
+			}
+			continue
+		default:
+			continue
+		}
+
+		item := importItem{key: strconv.Itoa(i)}
+		item.task.Title = strings.TrimSpace(get("content"))
+		item.task.Notes = get("description")
+		item.task.Status = "needsAction"
+		item.task.Meta.Priority = todoistPriority(get("priority"), false)
+
+		indent, _ := strconv.Atoi(get("indent"))
+		indent = max(indent, 1)
+		if indent > len(stack)+1 {
+			indent = len(stack) + 1
+		}
+		stack = append(stack[:indent-1], item.key)
+		if indent > 1 {
+			item.parentKey = stack[indent-2]
+		}
+
+		if date := get("date"); date != "" {
+			due, err := importDate(date, loc)
+			switch {
+			case strings.HasPrefix(strings.ToLower(date), "every"):
+				warnings = append(warnings, fmt.Sprintf("\"%s\" repeats %q in Todoist; imported once, set it up again as a recurring task", item.task.Title, date))
+			case err != nil:
+				warnings = append(warnings, fmt.Sprintf("couldn't read the date %q of \"%s\"; imported without a due date", date, item.task.Title))
+			}
+			if err == nil {
+				item.task.Due = due
+			}
+		}
+		if n, err := strconv.Atoi(get("duration")); err == nil && n > 0 && strings.EqualFold(get("duration_unit"), "minute") {
+			item.task.Meta.EstimateMinutes = n
+		}
+		if item.task.Title != "" {
+			items = append(items, item)
+		}
+	}
+	return items, warnings, nil
+}
+
+// todoistID accepts both the numeric ids of old backups and string ids.
+type todoistID string
+
+func (id *todoistID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = todoistID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = todoistID(n.String())
+	return nil
+}
+
+type todoistItem struct {
+	ID          todoistID `json:"id"`
+	ProjectID   todoistID `json:"project_id"`
+	ParentID    todoistID `json:"parent_id"`
+	Content     string    `json:"content"`
+	Description string    `json:"description"`
+	// Priority follows the API: 4 is p1.
+	Priority    int    `json:"priority"`
+	Checked     bool   `json:"checked"`
+	IsCompleted bool   `json:"is_completed"`
+	IsDeleted   bool   `json:"is_deleted"`
+	CompletedAt string `json:"completed_at"`
+	ChildOrder  int    `json:"child_order"`
+	Order       int    `json:"order"`
+	Due         *struct {
+		Date        string `json:"date"`
+		IsRecurring bool   `json:"is_recurring"`
+	} `json:"due"`
+	Duration *struct {
+		Amount int    `json:"amount"`
+		Unit   string `json:"unit"`
+	} `json:"duration"`
+}
+
+// parseTodoistJSON reads a Todoist backup or API dump: an object with
+// projects and items (or tasks), or a bare array of tasks.
+func parseTodoistJSON(data []byte, loc *time.Location) ([]importItem, []string, error) {
+	var backup struct {
+		Projects []struct {
+			ID   todoistID `json:"id"`
+			Name string    `json:"name"`
+		} `json:"projects"`
+		Items []todoistItem `json:"items"`
+		Tasks []todoistItem `json:"tasks"`
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &backup.Items); err != nil {
+			return nil, nil, fmt.Errorf("invalid Todoist JSON: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, nil, fmt.Errorf("invalid Todoist JSON: %w", err)
+	}
+
+	projects := make(map[todoistID]string, len(backup.Projects))
+	for _, p := range backup.Projects {
+		projects[p.ID] = strings.TrimSpace(p.Name)
+	}
+	all := append(backup.Items, backup.Tasks...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return max(all[i].ChildOrder, all[i].Order) < max(all[j].ChildOrder, all[j].Order)
+	})
+
+	var items []importItem
+	var warnings []string
+	for _, t := range all {
+		if t.IsDeleted || strings.TrimSpace(t.Content) == "" {
+			continue
+		}
+		item := importItem{
+			key:       string(t.ID),
+			parentKey: string(t.ParentID),
+			list:      projects[t.ProjectID],
+		}
+		item.task.Title = strings.TrimSpace(t.Content)
+		item.task.Notes = t.Description
+		item.task.Status = "needsAction"
+		item.task.Meta.Priority = todoistPriority(strconv.Itoa(t.Priority), true)
+		if t.Checked || t.IsCompleted {
+			item.task.Status = "completed"
+			item.task.Completed = parseTaskTime(t.CompletedAt)
+		}
+		if t.Due != nil && t.Due.Date != "" {
+			due, err := importDate(t.Due.Date, loc)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("couldn't read the due date %q of \"%s\"; imported without one", t.Due.Date, item.task.Title))
+			} else {
+				item.task.Due = due
+			}
+			if t.Due.IsRecurring {
+				warnings = append(warnings, fmt.Sprintf("\"%s\" repeats in Todoist; imported once, set it up again as a recurring task", item.task.Title))
+			}
+		}
+		if t.Duration != nil && t.Duration.Unit == "minute" {
+			item.task.Meta.EstimateMinutes = t.Duration.Amount
+		}
+		items = append(items, item)
+	}
+	return items, warnings, nil
+}
+
+// todoistPriority maps Todoist priorities to ours. CSV exports count p1 as
+// 1, the API counts it as 4; p4 means no priority in both.
+func todoistPriority(value string, api bool) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 || n > 4 {
+		return 0
+	}
+	if api {
+		n = 5 - n
+	}
+	if n == 4 {
+		return 0
+	}
+	return n
+}
+
+type taskwarriorTask struct {
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Project     string   `json:"project"`
+	Priority    string   `json:"priority"`
+	Status      string   `json:"status"`
+	Due         string   `json:"due"`
+	End         string   `json:"end"`
+	Tags        []string `json:"tags"`
+	Annotations []struct {
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// parseTaskwarrior reads `task export` output, a JSON array or one task per
+// line in older versions. Deleted tasks and recurrence templates are left
+// out; the pending instances of recurring tasks are imported.
+func parseTaskwarrior(data []byte, loc *time.Location) ([]importItem, []string, error) {
+	var tasks []taskwarriorTask
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			return nil, nil, fmt.Errorf("invalid Taskwarrior export: %w", err)
+		}
+	} else {
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimRight(bytes.TrimSpace(line), ",")
+			if len(line) == 0 {
+				continue
+			}
+			var t taskwarriorTask
+			if err := json.Unmarshal(line, &t); err != nil {
+				return nil, nil, fmt.Errorf("invalid Taskwarrior export: %w", err)
+			}
+			tasks = append(tasks, t)
+		}
+	}
+
+	var items []importItem
+	var warnings []string
+	for _, t := range tasks {
+		switch t.Status {
+		case "deleted":
+			continue
+		case "recurring":
+			warnings = append(warnings, fmt.Sprintf("recurring template \"%s\" skipped; set it up again as a recurring task", t.Description))
+			continue
+		}
+
+		item := importItem{key: t.UUID, list: strings.TrimSpace(t.Project)}
+		item.task.Title = strings.TrimSpace(t.Description)
+		item.task.Status = "needsAction"
+		if t.Status == "completed" {
+			item.task.Status = "completed"
+			item.task.Completed, _ = time.Parse("20060102T150405Z", t.End)
+		}
+		item.task.Meta.Priority = map[string]int{"H": 1, "M": 2, "L": 3}[strings.ToUpper(t.Priority)]
+		if t.Due != "" {
+			due, err := importDate(t.Due, loc)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("couldn't read the due date %q of \"%s\"; imported without one", t.Due, item.task.Title))
+			} else {
+				item.task.Due = due
+			}
+		}
+		var notes []string
+		for _, a := range t.Annotations {
+			notes = append(notes, a.Description)
+		}
+		if len(t.Tags) > 0 {
+			notes = append(notes, "Tags: "+strings.Join(t.Tags, ", "))
+		}
+		item.task.Notes = strings.Join(notes, "\n")
+		if item.task.Title != "" {
+			items = append(items, item)
+		}
+	}
+	return items, warnings, nil
+}
+
+// csvColumns maps the header names a plain CSV may use to our fields.
+var csvColumns = map[string][]string{
+	"title":    {"title", "task", "name", "content", "summary"},
+	"notes":    {"notes", "note", "description", "details"},
+	"due":      {"due", "due_date", "due date", "deadline", "date"},
+	"priority": {"priority", "prio"},
+	"list":     {"list", "project", "task_list", "tasklist", "category"},
+	"status":   {"status", "done", "completed", "state"},
+	"estimate": {"estimate", "estimate_minutes", "duration", "minutes"},
+}
+
+// parsePlainCSV reads a CSV with a header row. Only a title column is
+// required; see csvColumns for the names understood.
+func parsePlainCSV(data []byte, loc *time.Location) ([]importItem, []string, error) {
+	rows, col, err := readCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, aliases := range csvColumns {
+		for _, alias := range aliases {
+			if i, ok := col[alias]; ok {
+				if _, taken := col[name]; !taken || alias == name {
+					col[name] = i
+				}
+				break
+			}
+		}
+	}
+	if _, ok := col["title"]; !ok {
+		return nil, nil, fmt.Errorf("the CSV needs a title column (or task, name, content)")
+	}
+
+	var items []importItem
+	var warnings []string
+	for i, row := range rows {
+		get := func(name string) string { return field(row, col, name) }
+		item := importItem{key: strconv.Itoa(i), list: get("list")}
+		item.task.Title = get("title")
+		if item.task.Title == "" {
+			continue
+		}
+		item.task.Notes = get("notes")
+		item.task.Status = "needsAction"
+		switch strings.ToLower(get("status")) {
+		case "completed", "complete", "done", "x", "yes", "true", "1":
+			item.task.Status = "completed"
+		}
+		if v := get("priority"); v != "" {
+			p, err := taskmeta.ParsePriority(v)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("\"%s\": %v", item.task.Title, err))
+			}
+			item.task.Meta.Priority = p
+		}
+		if v := get("estimate"); v != "" {
+			n, err := taskmeta.ParseEstimate(v)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("\"%s\": %v", item.task.Title, err))
+			}
+			item.task.Meta.EstimateMinutes = n
+		}
+		if v := get("due"); v != "" {
+			due, err := importDate(v, loc)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("couldn't read the due date %q of \"%s\"; imported without one", v, item.task.Title))
+			} else {
+				item.task.Due = due
+			}
+		}
+		items = append(items, item)
+	}
+	return items, warnings, nil
+}
+
+// readCSV returns the rows after the header and the column index of each
+// lower-cased header name. Semicolon-separated files are accepted too.
+func readCSV(data []byte) ([][]string, map[string]int, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("the CSV is empty")
+	}
+	col := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := col[name]; !ok {
+			col[name] = i
+		}
+	}
+	return records[1:], col, nil
+}
+
+func field(row []string, col map[string]int, name string) string {
+	i, ok := col[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// importDate reads the dates found in exports and returns the day in loc as
+// a due date, i.e. midnight UTC.
+func importDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	var t time.Time
+	var err error
+	for _, layout := range []string{time.RFC3339, "20060102T150405Z"} {
+		if t, err = time.Parse(layout, value); err == nil {
+			t = t.In(loc)
+			break
+		}
+	}
+	if err != nil {
+		for _, layout := range []string{time.DateOnly, "2006-01-02T15:04:05", "2006-01-02 15:04"} {
+			if t, err = time.ParseInLocation(layout, value, loc); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if t, _, err = nldate.Parse(value, time.Now().In(loc)); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,262 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectImportFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"todoist csv", "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT\ntask,Buy milk,,4,1\n", FormatTodoistCSV},
+		{"plain csv", "title,due\nBuy milk,2025-12-11\n", FormatCSV},
+		{"taskwarrior array", `[{"uuid":"a1","description":"Buy milk","status":"pending"}]`, FormatTaskwarrior},
+		{"taskwarrior lines", "{\"uuid\":\"a1\",\"description\":\"Buy milk\"},\n{\"uuid\":\"b2\",\"description\":\"Pay rent\"}\n", FormatTaskwarrior},
+		{"todoist backup", `{"projects":[{"id":"1","name":"Home"}],"items":[{"id":"2","content":"Buy milk"}]}`, FormatTodoistJSON},
+		{"todoist tasks array", `[{"id":"2","content":"Buy milk","due":{"date":"2025-12-11"}}]`, FormatTodoistJSON},
+		{"todoist with nested uuid", `[{"id":"2","content":"Buy milk","meta":{"uuid":"x","description":"y"}}]`, FormatTodoistJSON},
+		{"empty array", `[]`, FormatTodoistJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectImportFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("detectImportFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// describeItems renders items as "list/title<parent|status|due|p|est|notes"
+// lines, resolving parent keys to titles.
+func describeItems(items []importItem) []string {
+	titles := make(map[string]string)
+	for _, item := range items {
+		titles[item.key] = item.task.Title
+	}
+	var res []string
+	for _, item := range items {
+		t := item.task
+		due := ""
+		if !t.Due.IsZero() {
+			due = t.Due.Format(time.DateOnly)
+		}
+		res = append(res, fmt.Sprintf("%s/%s<%s|%s|%s|p%d|%dm|%s",
+			item.list, t.Title, titles[item.parentKey], t.Status, due, t.Meta.Priority, t.Meta.EstimateMinutes, t.Notes))
+	}
+	return res
+}
+
+func TestImportParsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func([]byte, *time.Location) ([]importItem, []string, error)
+		data     string
+		want     []string
+		warnings int
+	}{
+		{
+			name:  "todoist csv",
+			parse: parseTodoistCSV,
+			data: `TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE,DURATION,DURATION_UNIT
+task,Plan party,Saturday,1,1,,,2025-12-20,en,,,
+note,Ask Ann first,,,,,,,,,,
+task,Book venue,,2,2,,,,en,,45,minute
+task,Send invites,,4,3,,,,en,,,
+task,Water plants,,4,1,,,every monday,en,,,
+section,Later,,,,,,,,,,
+`,
+			want: []string{
+				"/Plan party<|needsAction|2025-12-20|p1|0m|Saturday\nAsk Ann first",
+				"/Book venue<Plan party|needsAction||p2|45m|",
+				"/Send invites<Book venue|needsAction||p0|0m|",
+				"/Water plants<|needsAction||p0|0m|",
+			},
+			warnings: 1,
+		},
+		{
+			name:  "todoist json backup",
+			parse: parseTodoistJSON,
+			data: `{
+  "projects": [{"id": 10, "name": "Home"}],
+  "items": [
+    {"id": 2, "project_id": 10, "parent_id": 1, "content": "Book venue", "priority": 3, "child_order": 2, "duration": {"amount": 30, "unit": "minute"}},
+    {"id": 1, "project_id": 10, "content": "Plan party", "priority": 4, "child_order": 1, "due": {"date": "2025-12-20T18:00:00", "is_recurring": true}},
+    {"id": 3, "project_id": 10, "content": "Old idea", "is_deleted": true},
+    {"id": "4", "project_id": 10, "content": "Pay rent", "checked": true, "completed_at": "2025-12-01T09:00:00Z", "child_order": 3}
+  ]
+}`,
+			want: []string{
+				"Home/Plan party<|needsAction|2025-12-20|p1|0m|",
+				"Home/Book venue<Plan party|needsAction||p2|30m|",
+				"Home/Pay rent<|completed||p0|0m|",
+			},
+			warnings: 1,
+		},
+		{
+			name:  "taskwarrior",
+			parse: parseTaskwarrior,
+			data: `[
+{"uuid":"a","description":"Renew passport","project":"Admin","priority":"H","status":"pending","due":"20260115T230000Z","tags":["travel"],"annotations":[{"description":"Photos first"}]},
+{"uuid":"b","description":"Gone","status":"deleted"},
+{"uuid":"c","description":"Weekly review","status":"recurring"},
+{"uuid":"d","description":"Pay rent","status":"completed","end":"20251201T090000Z"}
+]`,
+			want: []string{
+				"Admin/Renew passport<|needsAction|2026-01-15|p1|0m|Photos first\nTags: travel",
+				"/Pay rent<|completed||p0|0m|",
+			},
+			warnings: 1,
+		},
+		{
+			name:  "plain csv with aliases and semicolons",
+			parse: parsePlainCSV,
+			data: "\ufeffTask;Project;Deadline;Prio;Done;Minutes;Details\n" +
+				"Buy milk;Home;2025-12-11;high;;;Oat\n" +
+				"Pay rent;Home;;;x;;\n" +
+				";Home;;;;;\n" +
+				"Call Bob;;someday;urgent;;1h;\n",
+			want: []string{
+				"Home/Buy milk<|needsAction|2025-12-11|p1|0m|Oat",
+				"Home/Pay rent<|completed||p0|0m|",
+				"/Call Bob<|needsAction||p0|60m|",
+			},
+			warnings: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, warnings, err := tt.parse([]byte(tt.data), time.UTC)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := describeItems(items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("items =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestImportParsersReject(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte, *time.Location) ([]importItem, []string, error)
+		data  string
+	}{
+		{"todoist csv without content", parseTodoistCSV, "TYPE,TITLE\ntask,Buy milk\n"},
+		{"plain csv without title", parsePlainCSV, "due,priority\n2025-12-11,1\n"},
+		{"empty csv", parsePlainCSV, ""},
+		{"todoist json", parseTodoistJSON, `{"items": [`},
+		{"taskwarrior line", parseTaskwarrior, "{\"uuid\":\"a\",\"description\":\"x\"}\nnot json\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if items, _, err := tt.parse([]byte(tt.data), time.UTC); err == nil {
+				t.Errorf("parse() = %v; want an error", items)
+			}
+		})
+	}
+}
+
+func TestTodoistPriority(t *testing.T) {
+	tests := []struct {
+		value string
+		api   bool
+		want  int
+	}{
+		{"1", false, 1},
+		{"4", false, 0},
+		{"4", true, 1},
+		{"1", true, 0},
+		{"2", true, 3},
+		{"", false, 0},
+		{"9", false, 0},
+	}
+	for _, tt := range tests {
+		if got := todoistPriority(tt.value, tt.api); got != tt.want {
+			t.Errorf("todoistPriority(%q, %v) = %d, want %d", tt.value, tt.api, got, tt.want)
+		}
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	const export = `title,list,done
+Buy milk,Home,
+Pay rent,Home,x
+Plan party,Party,
+Water plants,,
+`
+	tests := []struct {
+		name    string
+		opts    ImportOptions
+		created int
+		skipped int
+		lists   []string
+		want    string
+	}{
+		{
+			name:    "dry run writes nothing",
+			opts:    ImportOptions{List: "Home", DryRun: true},
+			skipped: 2,
+			lists:   []string{"Party"},
+			want:    "## Home\n- [ ] Buy milk 🆔 milk\n",
+		},
+		{
+			name:    "existing titles and completed tasks are skipped, new tasks go first",
+			opts:    ImportOptions{List: "Home"},
+			created: 2,
+			skipped: 2,
+			lists:   []string{"Party"},
+			want:    "## Home\n- [ ] Water plants\n- [ ] Buy milk 🆔 milk\n\n## Party\n- [ ] Plan party\n",
+		},
+		{
+			name:    "completed tasks on request",
+			opts:    ImportOptions{List: "Home", IncludeCompleted: true},
+			created: 3,
+			skipped: 1,
+			lists:   []string{"Party"},
+			want:    "## Home\n- [x] Pay rent ✅ <today>\n- [ ] Water plants\n- [ ] Buy milk 🆔 milk\n\n## Party\n- [ ] Plan party\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.md")
+			if err := os.WriteFile(path, []byte("## Home\n- [ ] Buy milk 🆔 milk\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			result, err := ImportTasks(context.Background(), NewMarkdownTaskProvider(path), strings.NewReader(export), tt.opts)
+			if err != nil {
+				t.Fatalf("ImportTasks: %v", err)
+			}
+			if result.Format != FormatCSV || result.Created != tt.created || result.Skipped != tt.skipped || result.Failed != 0 {
+				t.Errorf("result = %+v, want created %d, skipped %d", result, tt.created, tt.skipped)
+			}
+			if !reflect.DeepEqual(result.NewLists, tt.lists) {
+				t.Errorf("NewLists = %v, want %v", result.NewLists, tt.lists)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := generatedID.ReplaceAllString(string(data), "")
+			got = strings.ReplaceAll(got, " ➕ "+today(context.Background()).Format(time.DateOnly), "")
+			got = strings.ReplaceAll(got, today(context.Background()).Format(time.DateOnly), "<today>")
+			got = strings.ReplaceAll(got, " \n", "\n")
+			if got != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
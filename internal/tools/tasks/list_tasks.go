@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
+
+	"groundhog/internal/nldate"
+	"groundhog/internal/profile"
 
 	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
@@ -13,30 +17,39 @@ import (
 	"github.com/tmc/langchaingo/tools"
 )
 
-// ListTasks retrieves tasks from a Google Tasks list (defaults to @default).
+// ListTasks retrieves tasks from a task list (defaults to the default list).
 type ListTasks struct {
-	credFile string
+	provider TaskProvider
 }
 
 var _ tools.Tool = &ListTasks{}
 
-func NewListTasks(credFile string) *ListTasks {
+func NewListTasks(provider TaskProvider) *ListTasks {
 	return &ListTasks{
-		credFile: credFile,
+		provider: provider,
 	}
 }
 
+// Provider returns the backend the tool reads from.
+func (l *ListTasks) Provider() TaskProvider {
+	return l.provider
+}
+
 func (l *ListTasks) Name() string {
 	return "tasks"
 }
 
 func (l *ListTasks) Description() string {
-	return `List tasks from Google Tasks. Defaults to the primary list (@default).
+	return `List or search the user's tasks in their list order, with subtasks indented below their parent. Defaults to the open tasks of the primary list (@default).
 
 Optional fields:
-- task_list_id: specific task list id. Omit to use @default.
-- include_completed: set true to include completed/hidden tasks.
-- max_results: limit number of tasks returned (1-100, default 25).`
+- task_list_id: task list id or name. Omit to use @default.
+- status: needsAction (default), completed or all.
+- include_completed: set true to include completed/hidden tasks; same as status all.
+- query: only tasks whose title or notes contain this text.
+- due_min, due_max: due date range, YYYY-MM-DD or a relative expression like "next Friday"; both days are included.
+- completed_min, completed_max: completion date range, e.g. completed_min "monday" for what was finished this week. Implies completed tasks.
+- max_results: limit number of tasks shown (1-500, default 25). The answer says when more tasks matched.`
 }
 
 func (l *ListTasks) Parameters() map[string]interface{} {
@@ -45,15 +58,40 @@ func (l *ListTasks) Parameters() map[string]interface{} {
 		"properties": map[string]interface{}{
 			"task_list_id": map[string]interface{}{
 				"type":        "string",
-				"description": "Task list id; omit to use the default list (@default).",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+			"status": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"needsAction", "completed", "all"},
+				"description": "Which tasks to list; defaults to needsAction.",
 			},
 			"include_completed": map[string]interface{}{
 				"type":        "boolean",
 				"description": "Include completed tasks when true.",
 			},
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Text to find in the title or notes.",
+			},
+			"due_min": map[string]interface{}{
+				"type":        "string",
+				"description": "Earliest due date, YYYY-MM-DD or relative.",
+			},
+			"due_max": map[string]interface{}{
+				"type":        "string",
+				"description": "Latest due date (inclusive), YYYY-MM-DD or relative.",
+			},
+			"completed_min": map[string]interface{}{
+				"type":        "string",
+				"description": "Earliest completion date, YYYY-MM-DD, RFC3339 or relative.",
+			},
+			"completed_max": map[string]interface{}{
+				"type":        "string",
+				"description": "Latest completion date (inclusive), YYYY-MM-DD, RFC3339 or relative.",
+			},
 			"max_results": map[string]interface{}{
 				"type":        "integer",
-				"description": "Maximum tasks to return (1-100, default 25).",
+				"description": "Maximum tasks to show (1-500, default 25).",
 			},
 		},
 		"required": []string{},
@@ -70,67 +108,54 @@ func (l *ListTasks) Call(ctx context.Context, input string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	srv, err := newTasksService(ctx, l.credFile)
+	q, err := payload.query(profile.Location(ctx))
 	if err != nil {
 		return "", err
 	}
 
-	taskListID := strings.TrimSpace(payload.TaskListID)
-	if taskListID == "" {
-		taskListID = "@default"
-	}
-
-	maxResults := int64(25)
-	switch {
-	case payload.MaxResults > 100:
-		maxResults = 100
-	case payload.MaxResults > 0:
-		maxResults = int64(payload.MaxResults)
-	}
-
-	includeCompleted := payload.IncludeCompleted
-
-	listCall := srv.Tasks.List(taskListID).
-		ShowCompleted(includeCompleted).
-		ShowHidden(includeCompleted).
-		MaxResults(maxResults).
-		Context(ctx)
-
-	tasksResp, err := listCall.Do()
+	// Fetch everything that matches so the answer can tell how much was
+	// left out instead of silently truncating.
+	items, listTitle, err := l.Fetch(ctx, q)
 	if err != nil {
-		return "", fmt.Errorf("unable to retrieve tasks: %w", err)
-	}
-
-	var listTitle string = taskListID
-	if tl, err := srv.Tasklists.Get(taskListID).Context(ctx).Do(); err == nil && strings.TrimSpace(tl.Title) != "" {
-		listTitle = tl.Title
+		return "", err
 	}
 
-	if len(tasksResp.Items) == 0 {
+	if len(items) == 0 {
+		if q.Query != "" || q.Status != "" || !q.DueMin.IsZero() || !q.DueMax.IsZero() || !q.CompletedMin.IsZero() || !q.CompletedMax.IsZero() {
+			return fmt.Sprintf("No tasks in \"%s\" match the filters.", listTitle), nil
+		}
 		return fmt.Sprintf("No tasks found for \"%s\".", listTitle), nil
 	}
 
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("Tasks in \"%s\":\n", listTitle))
 
-	for _, t := range tasksResp.Items {
-		title := strings.TrimSpace(t.Title)
+	nested := nest(items)
+	for _, t := range nested[:min(len(nested), payload.MaxResults)] {
+		title := t.Title
 		if title == "" {
 			title = "(no title)"
 		}
-		status := strings.TrimSpace(t.Status)
-		if status == "" {
-			status = "needsAction"
-		}
-		due := strings.TrimSpace(t.Due)
 
+		// Subtasks are indented below their parent.
+		b.WriteString(strings.Repeat("  ", t.Depth))
 		b.WriteString("- ")
 		b.WriteString(title)
-		if due != "" {
-			b.WriteString(fmt.Sprintf(" | due: %s", due))
+		if !t.Due.IsZero() {
+			// Google Tasks only keeps the date part of due.
+			b.WriteString(fmt.Sprintf(" | due: %s", t.Due.UTC().Format(time.DateOnly)))
+		}
+		if meta := t.Meta.Summary(); meta != "" {
+			b.WriteString(" | " + meta)
 		}
-		b.WriteString(fmt.Sprintf(" | status: %s | id: %s\n", status, t.Id))
+		b.WriteString(fmt.Sprintf(" | status: %s", t.Status))
+		if !t.Completed.IsZero() {
+			b.WriteString(fmt.Sprintf(" | completed: %s", t.Completed.In(profile.Location(ctx)).Format(time.DateOnly)))
+		}
+		b.WriteString(fmt.Sprintf(" | id: %s\n", t.ID))
+	}
+	if more := len(nested) - payload.MaxResults; more > 0 {
+		b.WriteString(fmt.Sprintf("…and %d more matching tasks; raise max_results or narrow the filters to see them.\n", more))
 	}
 
 	return b.String(), nil
@@ -138,28 +163,108 @@ func (l *ListTasks) Call(ctx context.Context, input string) (string, error) {
 
 type listTasksInput struct {
 	TaskListID       string `json:"task_list_id"`
+	Status           string `json:"status"`
 	IncludeCompleted bool   `json:"include_completed"`
+	Query            string `json:"query"`
+	DueMin           string `json:"due_min"`
+	DueMax           string `json:"due_max"`
+	CompletedMin     string `json:"completed_min"`
+	CompletedMax     string `json:"completed_max"`
 	MaxResults       int    `json:"max_results"`
 }
 
 func parseListTasksInput(raw string) (listTasksInput, error) {
+	payload := listTasksInput{MaxResults: 25}
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
-		return listTasksInput{}, nil
+		return payload, nil
 	}
 
-	var payload listTasksInput
 	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
 		return listTasksInput{}, fmt.Errorf("invalid list tasks payload; expected a JSON object: %w", err)
 	}
 
-	if payload.MaxResults < 0 {
+	switch {
+	case payload.MaxResults < 0:
 		return listTasksInput{}, fmt.Errorf("max_results must be zero or positive")
+	case payload.MaxResults == 0:
+		payload.MaxResults = 25
+	case payload.MaxResults > 500:
+		payload.MaxResults = 500
+	}
+	switch payload.Status {
+	case "", "needsAction", "completed", "all":
+	default:
+		return listTasksInput{}, fmt.Errorf("status must be needsAction, completed or all")
 	}
 
 	return payload, nil
 }
 
+// query turns the input into a TaskQuery, resolving dates in loc. Due dates
+// are dates in UTC like Google stores them; completion bounds are instants.
+// Maximum dates include their whole day.
+func (in listTasksInput) query(loc *time.Location) (TaskQuery, error) {
+	q := TaskQuery{
+		TaskListID:       in.TaskListID,
+		IncludeCompleted: in.IncludeCompleted || in.Status == "all",
+		Query:            strings.TrimSpace(in.Query),
+	}
+	if in.Status == "needsAction" || in.Status == "completed" {
+		q.Status = in.Status
+	}
+
+	bounds := []struct {
+		name, value string
+		into        *time.Time
+		utc, max    bool
+	}{
+		{"due_min", in.DueMin, &q.DueMin, true, false},
+		{"due_max", in.DueMax, &q.DueMax, true, true},
+		{"completed_min", in.CompletedMin, &q.CompletedMin, false, false},
+		{"completed_max", in.CompletedMax, &q.CompletedMax, false, true},
+	}
+	for _, b := range bounds {
+		if strings.TrimSpace(b.value) == "" {
+			continue
+		}
+		t, dateOnly, err := parseDateBound(b.value, loc)
+		if err != nil {
+			return TaskQuery{}, fmt.Errorf("invalid %s: %w", b.name, err)
+		}
+		if dateOnly {
+			if b.utc {
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			}
+			if b.max {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		*b.into = t
+	}
+	return q, nil
+}
+
+// parseDateBound reads RFC3339, YYYY-MM-DD or a relative expression.
+// Dates come back as midnight in loc.
+func parseDateBound(value string, loc *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, true, nil
+	}
+	t, dateOnly, err := nldate.Parse(value, time.Now().In(loc))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("could not parse %q; use RFC3339, YYYY-MM-DD, or an expression like \"monday\"", value)
+	}
+	if dateOnly {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	return t, dateOnly, nil
+}
+
 func ensureContext(ctx context.Context) context.Context {
 	if ctx == nil {
 		return context.Background()
@@ -0,0 +1,736 @@
+package tasks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"groundhog/internal/oplog"
+	"groundhog/internal/profile"
+	"groundhog/internal/taskmeta"
+)
+
+// defaultMarkdownList holds the tasks written before the first heading.
+const defaultMarkdownList = "Tasks"
+
+// MarkdownTaskProvider keeps tasks in a markdown file in the notes directory,
+// written the way the Obsidian Tasks plugin writes them:
+//
+//	## Home
+//	- [ ] Call the venue ⏫ 📅 2025-12-11 🆔 a1b2c3
+//	  Ask about the deposit.
+//	  - [ ] Compare offers [estimate:: 30m]
+//	- [x] Pay rent ✅ 2025-12-01
+//
+// Headings are task lists, indented tasks are subtasks and indented plain
+// lines are the notes of the task above. todo.txt "(A)" priorities and
+// "due:" dates are read too. Tasks without a 🆔 get one written into their
+// line the first time they are read, so ids stay stable when a task is
+// renamed or moved.
+type MarkdownTaskProvider struct {
+	path string
+
+	mu sync.Mutex
+}
+
+var _ TaskProvider = &MarkdownTaskProvider{}
+
+func NewMarkdownTaskProvider(path string) *MarkdownTaskProvider {
+	return &MarkdownTaskProvider{
+		path: path,
+	}
+}
+
+func (m *MarkdownTaskProvider) ListTasks(ctx context.Context, q TaskQuery) ([]Task, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return nil, "", err
+	}
+	list, err := f.list(q.TaskListID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var res []Task
+	for _, t := range list.tasks {
+		if q.full(len(res)) {
+			break
+		}
+		if q.matches(t.Task) {
+			res = append(res, t.Task)
+		}
+	}
+	return res, list.title, nil
+}
+
+func (m *MarkdownTaskProvider) InsertTask(ctx context.Context, task Task, previous string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return Task{}, err
+	}
+	list, err := f.list(task.ListID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	task.ID = newMarkdownID()
+	task.ListID = list.title
+	if task.Status == "" {
+		task.Status = "needsAction"
+	}
+	task.Updated = today(ctx)
+	task.Due = dueDay(ctx, task.Due)
+	if task.Status == "completed" {
+		task.Completed = task.Updated
+	}
+
+	at, indent, err := f.placement(list, task.Parent, previous)
+	if err != nil {
+		return Task{}, err
+	}
+	f.insert(at, formatMarkdownTask(task, indent))
+	if err := m.save(f); err != nil {
+		return Task{}, err
+	}
+	recordMarkdownTask(ctx, opCreateMarkdownTask, task.ID, fmt.Sprintf("Create task %q", task.Title), nil, &task)
+	return task, nil
+}
+
+func (m *MarkdownTaskProvider) GetTask(ctx context.Context, listID, id string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return Task{}, err
+	}
+	_, t, err := f.find(listID, id)
+	if err != nil {
+		return Task{}, err
+	}
+	return t.Task, nil
+}
+
+func (m *MarkdownTaskProvider) UpdateTask(ctx context.Context, task Task) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return Task{}, err
+	}
+	_, existing, err := f.find(task.ListID, task.ID)
+	if err != nil {
+		return Task{}, err
+	}
+
+	updated := existing.Task
+	updated.Title, updated.Notes, updated.Meta, updated.Due = task.Title, task.Notes, task.Meta, dueDay(ctx, task.Due)
+	if task.Status != updated.Status {
+		updated.Status = task.Status
+		updated.Completed = time.Time{}
+		if task.Status == "completed" {
+			updated.Completed = today(ctx)
+		}
+	}
+	f.rewrite(existing, updated)
+	if err := m.save(f); err != nil {
+		return Task{}, err
+	}
+	recordMarkdownTask(ctx, opUpdateMarkdownTask, task.ID, fmt.Sprintf("Update task %q", updated.Title), &existing.Task, &updated)
+	return updated, nil
+}
+
+func (m *MarkdownTaskProvider) DeleteTask(ctx context.Context, listID, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return err
+	}
+	list, t, err := f.find(listID, id)
+	if err != nil {
+		return err
+	}
+	end := f.subtreeEnd(t)
+	removed := markdownBlock{
+		loggedMove: loggedMove{ListID: list.title, Parent: t.Parent, Previous: list.previous(t)},
+		Lines:      append([]string(nil), f.lines[t.line:end]...),
+	}
+	f.lines = append(f.lines[:t.line], f.lines[end:]...)
+	if err := m.save(f); err != nil {
+		return err
+	}
+	if err := oplog.Record(ctx, opDeleteMarkdownTask, id, fmt.Sprintf("Delete task %q", t.Title), removed, nil); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+	return nil
+}
+
+func (m *MarkdownTaskProvider) MoveTask(ctx context.Context, listID, id, parent, previous string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return Task{}, err
+	}
+	list, t, err := f.find(listID, id)
+	if err != nil {
+		return Task{}, err
+	}
+	before := loggedMove{ListID: list.title, Parent: t.Parent, Previous: list.previous(t)}
+	moved, err := f.move(list, t, parent, previous)
+	if err != nil {
+		return Task{}, err
+	}
+	if err := m.save(f); err != nil {
+		return Task{}, err
+	}
+	after := loggedMove{ListID: list.title, Parent: parent, Previous: previous}
+	if err := oplog.Record(ctx, opMoveMarkdownTask, id, fmt.Sprintf("Move task %q", t.Title), before, after); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+	return moved, nil
+}
+
+// CreateList adds a heading for title; list ids are their titles.
+func (m *MarkdownTaskProvider) CreateList(ctx context.Context, title string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return "", err
+	}
+	if l, err := f.list(title); err == nil && strings.TrimSpace(title) != "" {
+		return l.title, nil
+	}
+	l := f.addList(title)
+	if err := m.save(f); err != nil {
+		return "", err
+	}
+	if err := oplog.Record(ctx, opCreateMarkdownList, l.title, fmt.Sprintf("Create task list %q", l.title), nil, l.title); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+	return l.title, nil
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	markdownTaskLine = regexp.MustCompile(`^(\s*)[-*+]\s+\[(.)\]\s+(.*)$`)
+	markdownID       = regexp.MustCompile(`🆔\s*([A-Za-z0-9_-]+)`)
+	markdownDate     = regexp.MustCompile(`(📅|🗓|⏳|🛫|✅|➕|❌)\x{FE0F}?\s*(\d{4}-\d{2}-\d{2})`)
+	markdownPrio     = regexp.MustCompile(`(🔺|⏫|🔼|🔽|⏬)\x{FE0F}?`)
+	markdownRepeat   = regexp.MustCompile(`🔁\x{FE0F}?[^📅🗓⏳🛫✅➕❌🆔⛔]*`)
+	markdownDepends  = regexp.MustCompile(`⛔\x{FE0F}?\s*[A-Za-z0-9_,-]+`)
+	markdownField    = regexp.MustCompile(`\[(\w+)::\s*([^\]]*)\]`)
+	todoPriority     = regexp.MustCompile(`^\(([A-Z])\)\s+`)
+	todoDate         = regexp.MustCompile(`(?:^|\s)(due|t):(\d{4}-\d{2}-\d{2})\b`)
+)
+
+// markdownFile is the parsed task file. Lines are kept verbatim so writing
+// it back only changes what was inserted.
+type markdownFile struct {
+	lines []string
+	lists []*markdownList
+	// assigned is set when parsing wrote new 🆔 markers into lines.
+	assigned bool
+}
+
+type markdownList struct {
+	title string
+	// heading is the line of the heading, -1 for tasks above the first one.
+	heading int
+	// end is the line after the list's section.
+	end   int
+	tasks []*markdownTask
+}
+
+type markdownTask struct {
+	Task
+	line   int
+	indent int
+}
+
+func (m *MarkdownTaskProvider) load() (*markdownFile, error) {
+	data, err := os.ReadFile(m.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read tasks file: %w", err)
+	}
+	content := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	var lines []string
+	if content != "" {
+		lines = strings.Split(content, "\n")
+	}
+	f := parseMarkdownTasks(lines)
+	if f.assigned {
+		if err := m.save(f); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (m *MarkdownTaskProvider) save(f *markdownFile) error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o700); err != nil {
+		return fmt.Errorf("unable to create tasks directory: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(f.lines, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("unable to save tasks file: %w", err)
+	}
+	return os.Rename(tmp, m.path)
+}
+
+func parseMarkdownTasks(lines []string) *markdownFile {
+	f := &markdownFile{lines: lines}
+	current := &markdownList{title: defaultMarkdownList, heading: -1}
+	var stack []*markdownTask
+
+	closeList := func(end int) {
+		current.end = end
+		if current.heading >= 0 || len(current.tasks) > 0 {
+			f.lists = append(f.lists, current)
+		}
+	}
+
+	for i, line := range lines {
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			closeList(i)
+			current = &markdownList{title: match[1], heading: i}
+			stack = nil
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentWidth(line)
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		match := markdownTaskLine.FindStringSubmatch(line)
+		if match == nil {
+			// Indented text belongs to the task above as notes.
+			if len(stack) > 0 {
+				owner := stack[len(stack)-1]
+				owner.Notes = strings.TrimSpace(owner.Notes + "\n" + strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		t := &markdownTask{Task: parseMarkdownTask(match[2], match[3]), line: i, indent: indent}
+		t.ListID = current.title
+		t.Position = fmt.Sprintf("%08d", i)
+		if len(stack) > 0 {
+			t.Parent = stack[len(stack)-1].ID
+		}
+		if t.ID == "" {
+			t.ID = newMarkdownID()
+			lines[i] = strings.TrimRight(line, " \t") + " 🆔 " + t.ID
+			f.assigned = true
+		}
+		current.tasks = append(current.tasks, t)
+		stack = append(stack, t)
+	}
+	closeList(len(lines))
+	return f
+}
+
+// parseMarkdownTask reads the checkbox state and the description of a task
+// line.
+func parseMarkdownTask(box, desc string) Task {
+	t := Task{Status: "needsAction"}
+	if box == "x" || box == "X" || box == "-" {
+		t.Status = "completed"
+	}
+
+	if match := markdownID.FindStringSubmatch(desc); match != nil {
+		t.ID = match[1]
+	}
+	for _, match := range markdownDate.FindAllStringSubmatch(desc, -1) {
+		date, err := time.Parse(time.DateOnly, match[2])
+		if err != nil {
+			continue
+		}
+		switch match[1] {
+		case "📅", "🗓":
+			t.Due = date
+		case "🛫":
+			t.Meta.Start = match[2]
+		case "⏳":
+			if t.Meta.Start == "" {
+				t.Meta.Start = match[2]
+			}
+		case "✅", "❌":
+			t.Completed = date
+		case "➕":
+			t.Updated = date
+		}
+	}
+	if match := markdownPrio.FindStringSubmatch(desc); match != nil {
+		t.Meta.Priority = map[string]int{"🔺": 1, "⏫": 1, "🔼": 2, "🔽": 3, "⏬": 3}[match[1]]
+	}
+	for _, match := range markdownField.FindAllStringSubmatch(desc, -1) {
+		switch strings.ToLower(match[1]) {
+		case "estimate":
+			t.Meta.EstimateMinutes, _ = taskmeta.ParseEstimate(match[2])
+		case "energy":
+			t.Meta.Energy = strings.ToLower(strings.TrimSpace(match[2]))
+		case "goal":
+			t.Meta.Goal = strings.TrimSpace(match[2])
+		}
+	}
+	if match := todoPriority.FindStringSubmatch(desc); match != nil {
+		t.Meta.Priority = min(int(match[1][0]-'A')+1, 3)
+	}
+	for _, match := range todoDate.FindAllStringSubmatch(desc, -1) {
+		if match[1] == "due" {
+			t.Due, _ = time.Parse(time.DateOnly, match[2])
+		} else if t.Meta.Start == "" {
+			t.Meta.Start = match[2]
+		}
+	}
+
+	title := desc
+	for _, re := range []*regexp.Regexp{markdownID, markdownDate, markdownPrio, markdownRepeat, markdownDepends, markdownField, todoPriority, todoDate} {
+		title = re.ReplaceAllString(title, " ")
+	}
+	t.Title = strings.Join(strings.Fields(title), " ")
+	return t
+}
+
+// formatMarkdownTask renders task as its line and indented note lines.
+func formatMarkdownTask(t Task, indent string) []string {
+	box := " "
+	if t.Status == "completed" {
+		box = "x"
+	}
+	parts := []string{fmt.Sprintf("%s- [%s] %s", indent, box, t.Title)}
+	if t.Meta.EstimateMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("[estimate:: %dm]", t.Meta.EstimateMinutes))
+	}
+	if t.Meta.Energy != "" {
+		parts = append(parts, fmt.Sprintf("[energy:: %s]", t.Meta.Energy))
+	}
+	if t.Meta.Goal != "" {
+		parts = append(parts, fmt.Sprintf("[goal:: %s]", strings.ReplaceAll(t.Meta.Goal, "]", ")")))
+	}
+	switch p := t.Meta.Priority; {
+	case p == 1:
+		parts = append(parts, "⏫")
+	case p == 2:
+		parts = append(parts, "🔼")
+	case p >= 3:
+		parts = append(parts, "🔽")
+	}
+	if !t.Updated.IsZero() {
+		parts = append(parts, "➕ "+t.Updated.Format(time.DateOnly))
+	}
+	if t.Meta.Start != "" {
+		start := t.Meta.Start
+		if st, err := time.Parse(time.RFC3339, start); err == nil {
+			start = st.Format(time.DateOnly)
+		}
+		parts = append(parts, "🛫 "+start)
+	}
+	if !t.Due.IsZero() {
+		parts = append(parts, "📅 "+t.Due.UTC().Format(time.DateOnly))
+	}
+	if !t.Completed.IsZero() {
+		parts = append(parts, "✅ "+t.Completed.Format(time.DateOnly))
+	}
+	parts = append(parts, "🆔 "+t.ID)
+
+	lines := []string{strings.Join(parts, " ")}
+	for _, note := range strings.Split(t.Notes, "\n") {
+		if note = strings.TrimSpace(note); note != "" {
+			lines = append(lines, indent+"  "+note)
+		}
+	}
+	return lines
+}
+
+// list resolves a list title. Empty or @default selects the first list with
+// tasks, so a title heading at the top of the file isn't mistaken for one.
+func (f *markdownFile) list(name string) (*markdownList, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == defaultTaskList {
+		for _, l := range f.lists {
+			if len(l.tasks) > 0 {
+				return l, nil
+			}
+		}
+		if len(f.lists) == 0 {
+			return &markdownList{title: defaultMarkdownList, heading: -1, end: len(f.lines)}, nil
+		}
+		return f.lists[0], nil
+	}
+	var titles []string
+	for _, l := range f.lists {
+		if strings.EqualFold(l.title, name) {
+			return l, nil
+		}
+		titles = append(titles, l.title)
+	}
+	return nil, fmt.Errorf("%w %q; available lists: %s", ErrUnknownList, name, strings.Join(titles, ", "))
+}
+
+// addList appends a heading for a new list at the end of the file.
+func (f *markdownFile) addList(title string) *markdownList {
+	if len(f.lines) > 0 {
+		f.lines = append(f.lines, "")
+	}
+	f.lines = append(f.lines, "## "+strings.TrimSpace(title))
+	l := &markdownList{title: strings.TrimSpace(title), heading: len(f.lines) - 1, end: len(f.lines)}
+	f.lists = append(f.lists, l)
+	return l
+}
+
+// insertionPoint returns where a first top-level task goes: before the
+// list's first task, or below the rest of its section when it has none.
+func (f *markdownFile) insertionPoint(l *markdownList, empty bool) (int, string) {
+	if !empty {
+		first := l.tasks[0]
+		return first.line, leadingSpace(f.lines[first.line])
+	}
+	at := l.end
+	for at > l.heading+1 && strings.TrimSpace(f.lines[at-1]) == "" {
+		at--
+	}
+	return at, ""
+}
+
+// childPoint returns where a first task below parent goes; a nil parent
+// means the top level of l.
+func (f *markdownFile) childPoint(l *markdownList, parent *markdownTask) (int, string) {
+	if parent == nil {
+		return f.insertionPoint(l, len(l.tasks) == 0)
+	}
+	for _, t := range l.tasks {
+		if t.Parent == parent.ID {
+			return t.line, leadingSpace(f.lines[t.line])
+		}
+	}
+	return f.subtreeEnd(parent), leadingSpace(f.lines[parent.line]) + "  "
+}
+
+// subtreeEnd returns the line after t's notes and subtasks.
+func (f *markdownFile) subtreeEnd(t *markdownTask) int {
+	end := t.line + 1
+	for i := t.line + 1; i < len(f.lines); i++ {
+		line := f.lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if markdownHeading.MatchString(line) || indentWidth(line) <= t.indent {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+// find returns the task id with its list. Ids are unique in the file, so
+// a task outside listID is found too.
+func (f *markdownFile) find(listID, id string) (*markdownList, *markdownTask, error) {
+	if l, err := f.list(listID); err == nil {
+		if t := l.find(id); t != nil {
+			return l, t, nil
+		}
+	}
+	for _, l := range f.lists {
+		if t := l.find(id); t != nil {
+			return l, t, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %q", ErrTaskNotFound, id)
+}
+
+// placement returns where a task goes below parent (empty for the top
+// level) right after the sibling previous, and the indent of its line.
+func (f *markdownFile) placement(l *markdownList, parent, previous string) (int, string, error) {
+	var p *markdownTask
+	if parent != "" {
+		if p = l.find(parent); p == nil {
+			return 0, "", fmt.Errorf("unable to find parent task %q in list \"%s\"", parent, l.title)
+		}
+	}
+	at, indent := f.childPoint(l, p)
+	if previous != "" {
+		sibling := l.find(previous)
+		if sibling == nil || sibling.Parent != parent {
+			return 0, "", fmt.Errorf("task %q is not a sibling of the task", previous)
+		}
+		at = f.subtreeEnd(sibling)
+	}
+	return at, indent, nil
+}
+
+func (f *markdownFile) insert(at int, lines []string) {
+	f.lines = append(f.lines[:at], append(lines, f.lines[at:]...)...)
+}
+
+// rewrite replaces the line and notes of t with updated. Recurrence and
+// dependency markers, which tasks don't model, are kept.
+func (f *markdownFile) rewrite(t *markdownTask, updated Task) {
+	old := f.lines[t.line]
+	lines := formatMarkdownTask(updated, leadingSpace(old))
+	var keep []string
+	for _, re := range []*regexp.Regexp{markdownRepeat, markdownDepends} {
+		if marker := strings.TrimSpace(re.FindString(old)); marker != "" {
+			keep = append(keep, marker)
+		}
+	}
+	if len(keep) > 0 {
+		lines[0] = strings.Replace(lines[0], " 🆔 ", " "+strings.Join(keep, " ")+" 🆔 ", 1)
+	}
+	end := f.notesEnd(t)
+	f.lines = append(f.lines[:t.line], append(lines, f.lines[end:]...)...)
+}
+
+// move puts the subtree of t below parent after previous and returns t as
+// it reads afterwards. f is parsed again, so earlier pointers are stale.
+func (f *markdownFile) move(l *markdownList, t *markdownTask, parent, previous string) (Task, error) {
+	for p := parent; p != ""; {
+		if p == t.ID {
+			return Task{}, fmt.Errorf("a task can't move below itself or its subtasks")
+		}
+		pt := l.find(p)
+		if pt == nil {
+			break
+		}
+		p = pt.Parent
+	}
+	if previous == t.ID {
+		return Task{}, fmt.Errorf("a task can't be its own predecessor")
+	}
+
+	end := f.subtreeEnd(t)
+	block := append([]string(nil), f.lines[t.line:end]...)
+	f.lines = append(f.lines[:t.line], f.lines[end:]...)
+	*f = *parseMarkdownTasks(f.lines)
+
+	l, err := f.list(l.title)
+	if err != nil {
+		return Task{}, err
+	}
+	at, indent, err := f.placement(l, parent, previous)
+	if err != nil {
+		return Task{}, err
+	}
+	f.insert(at, reindent(block, indent))
+	*f = *parseMarkdownTasks(f.lines)
+	_, moved, err := f.find(l.title, t.ID)
+	if err != nil {
+		return Task{}, err
+	}
+	return moved.Task, nil
+}
+
+// notesEnd returns the line after t's notes, before its first subtask.
+func (f *markdownFile) notesEnd(t *markdownTask) int {
+	end := t.line + 1
+	for i := t.line + 1; i < len(f.lines); i++ {
+		line := f.lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if markdownHeading.MatchString(line) || indentWidth(line) <= t.indent || markdownTaskLine.MatchString(line) {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+// previous returns the id of the sibling right before t, or "".
+func (l *markdownList) previous(t *markdownTask) string {
+	previous := ""
+	for _, s := range l.tasks {
+		if s == t {
+			return previous
+		}
+		if s.Parent == t.Parent {
+			previous = s.ID
+		}
+	}
+	return ""
+}
+
+// reindent moves block, whose first line is a task, to indent.
+func reindent(block []string, indent string) []string {
+	old := leadingSpace(block[0])
+	res := make([]string, len(block))
+	for i, line := range block {
+		switch {
+		case strings.TrimSpace(line) == "":
+			res[i] = line
+		case strings.HasPrefix(line, old):
+			res[i] = indent + line[len(old):]
+		default:
+			res[i] = indent + strings.TrimLeft(line, " \t")
+		}
+	}
+	return res
+}
+
+func (l *markdownList) find(id string) *markdownTask {
+	for _, t := range l.tasks {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// indentWidth counts leading whitespace with tabs as four spaces.
+func indentWidth(line string) int {
+	return len(strings.ReplaceAll(leadingSpace(line), "\t", "    "))
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// today is the user's current day as the midnight UTC the file's dates
+// parse to; the server's own day may differ.
+func today(ctx context.Context) time.Time {
+	now := time.Now().In(profile.Location(ctx))
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dueDay turns due into the date the file stores. Date-only due dates are
+// already midnight UTC; timed ones keep their day in the user's time zone.
+func dueDay(ctx context.Context, due time.Time) time.Time {
+	if due.IsZero() || due.Equal(due.UTC().Truncate(24*time.Hour)) {
+		return due.UTC()
+	}
+	local := due.In(profile.Location(ctx))
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func newMarkdownID() string {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano()&0xffffff)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,291 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"groundhog/internal/profile"
+	"groundhog/internal/taskmeta"
+)
+
+func TestParseMarkdownTask(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+	tests := []struct {
+		box, desc string
+		want      Task
+	}{
+		{
+			box:  " ",
+			desc: "Call the venue ⏫ 📅 2025-12-11 🆔 a1b2c3",
+			want: Task{ID: "a1b2c3", Title: "Call the venue", Status: "needsAction", Due: date("2025-12-11"), Meta: taskmeta.Meta{Priority: 1}},
+		},
+		{
+			box:  "x",
+			desc: "Pay rent 🔁 every month ➕ 2025-11-20 ✅ 2025-12-01 🆔 rent",
+			want: Task{ID: "rent", Title: "Pay rent", Status: "completed", Updated: date("2025-11-20"), Completed: date("2025-12-01")},
+		},
+		{
+			box:  " ",
+			desc: "Compare offers [estimate:: 1.5h] [energy:: Low] [goal:: Wedding] 🛫 2025-12-12 ⛔ a1b2c3",
+			want: Task{Title: "Compare offers", Status: "needsAction", Meta: taskmeta.Meta{EstimateMinutes: 90, Energy: taskmeta.EnergyLow, Goal: "Wedding", Start: "2025-12-12"}},
+		},
+		{
+			box:  " ",
+			desc: "(B) Renew passport due:2026-01-15 t:2026-01-01",
+			want: Task{Title: "Renew passport", Status: "needsAction", Due: date("2026-01-15"), Meta: taskmeta.Meta{Priority: 2, Start: "2026-01-01"}},
+		},
+		{
+			box:  "-",
+			desc: "Cancelled plan ❌ 2025-12-02",
+			want: Task{Title: "Cancelled plan", Status: "completed", Completed: date("2025-12-02")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := parseMarkdownTask(tt.box, tt.desc); got != tt.want {
+				t.Errorf("parseMarkdownTask(%q, %q) =\n%+v\nwant\n%+v", tt.box, tt.desc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMarkdownTask(t *testing.T) {
+	due, _ := time.Parse(time.DateOnly, "2025-12-11")
+	tests := []struct {
+		name   string
+		task   Task
+		indent string
+		want   []string
+	}{
+		{
+			name: "plain",
+			task: Task{ID: "a1", Title: "Buy milk", Status: "needsAction"},
+			want: []string{"- [ ] Buy milk 🆔 a1"},
+		},
+		{
+			name:   "every field",
+			indent: "  ",
+			task: Task{
+				ID: "b2", Title: "Call the venue", Status: "completed",
+				Notes:     "Ask about the deposit.\n\nAnd parking.",
+				Due:       due,
+				Updated:   due.AddDate(0, 0, -3),
+				Completed: due,
+				Meta:      taskmeta.Meta{EstimateMinutes: 45, Energy: "low", Goal: "Wed[ding]", Priority: 2, Start: "2025-12-10T09:00:00Z"},
+			},
+			want: []string{
+				"  - [x] Call the venue [estimate:: 45m] [energy:: low] [goal:: Wed[ding)] 🔼 ➕ 2025-12-08 🛫 2025-12-10 📅 2025-12-11 ✅ 2025-12-11 🆔 b2",
+				"    Ask about the deposit.",
+				"    And parking.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatMarkdownTask(tt.task, tt.indent)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("formatMarkdownTask() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseMarkdownTasks(t *testing.T) {
+	lines := strings.Split(`# Notes
+
+- [ ] Loose task 🆔 loose
+## Home
+- [ ] Call the venue 🆔 venue
+  Ask about the deposit.
+  - [ ] Compare offers 🆔 offers
+    Two quotes so far.
+- [x] Pay rent 🆔 rent
+
+## Work
+* [ ] Write report`, "\n")
+
+	f := parseMarkdownTasks(lines)
+	if !f.assigned {
+		t.Error("a task without an id didn't get one")
+	}
+	if !strings.Contains(f.lines[len(f.lines)-1], "🆔 ") {
+		t.Errorf("assigned id wasn't written into the line: %q", f.lines[len(f.lines)-1])
+	}
+
+	var titles []string
+	for _, l := range f.lists {
+		titles = append(titles, l.title)
+	}
+	if got, want := strings.Join(titles, ","), "Notes,Home,Work"; got != want {
+		t.Fatalf("lists = %s, want %s", got, want)
+	}
+
+	home := f.lists[1]
+	tests := []struct {
+		id, parent, notes, status string
+	}{
+		{"venue", "", "Ask about the deposit.", "needsAction"},
+		{"offers", "venue", "Two quotes so far.", "needsAction"},
+		{"rent", "", "", "completed"},
+	}
+	if len(home.tasks) != len(tests) {
+		t.Fatalf("Home has %d tasks, want %d", len(home.tasks), len(tests))
+	}
+	for i, tt := range tests {
+		got := home.tasks[i]
+		if got.ID != tt.id || got.Parent != tt.parent || got.Notes != tt.notes || got.Status != tt.status || got.ListID != "Home" {
+			t.Errorf("Home task %d = %+v, want id %q parent %q notes %q status %q", i, got.Task, tt.id, tt.parent, tt.notes, tt.status)
+		}
+	}
+
+	if l, err := f.list(""); err != nil || l.title != "Notes" {
+		t.Errorf("default list = %v, %v; want Notes", l, err)
+	}
+	if _, err := f.list("Garden"); !errors.Is(err, ErrUnknownList) {
+		t.Errorf("list(Garden) error = %v, want ErrUnknownList", err)
+	}
+}
+
+// generatedID matches the ids newMarkdownID writes.
+var generatedID = regexp.MustCompile(`🆔 [0-9a-f]{6}\b`)
+
+func TestMarkdownTaskProvider(t *testing.T) {
+	const initial = `## Home
+- [ ] Call the venue 🔁 every week ➕ 2025-12-01 🆔 venue
+  Ask about the deposit.
+  - [ ] Compare offers 🆔 offers
+- [ ] Pay rent 🆔 rent
+`
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, p *MarkdownTaskProvider) error
+		want string
+	}{
+		{
+			name: "insert subtask after sibling",
+			run: func(ctx context.Context, p *MarkdownTaskProvider) error {
+				_, err := p.InsertTask(ctx, Task{ListID: "Home", Title: "Sign contract", Parent: "venue"}, "offers")
+				return err
+			},
+			want: `## Home
+- [ ] Call the venue 🔁 every week ➕ 2025-12-01 🆔 venue
+  Ask about the deposit.
+  - [ ] Compare offers 🆔 offers
+  - [ ] Sign contract ➕ <today> 🆔 <id>
+- [ ] Pay rent 🆔 rent
+`,
+		},
+		{
+			name: "update keeps recurrence, added date and subtasks",
+			run: func(ctx context.Context, p *MarkdownTaskProvider) error {
+				task, err := p.GetTask(ctx, "Home", "venue")
+				if err != nil {
+					return err
+				}
+				task.Title = "Call the caterer"
+				task.Notes = ""
+				task.Status = "completed"
+				_, err = p.UpdateTask(ctx, task)
+				return err
+			},
+			want: `## Home
+- [x] Call the caterer ➕ 2025-12-01 ✅ <today> 🔁 every week 🆔 venue
+  - [ ] Compare offers 🆔 offers
+- [ ] Pay rent 🆔 rent
+`,
+		},
+		{
+			name: "move subtree below another task",
+			run: func(ctx context.Context, p *MarkdownTaskProvider) error {
+				_, err := p.MoveTask(ctx, "Home", "venue", "rent", "")
+				return err
+			},
+			want: `## Home
+- [ ] Pay rent 🆔 rent
+  - [ ] Call the venue 🔁 every week ➕ 2025-12-01 🆔 venue
+    Ask about the deposit.
+    - [ ] Compare offers 🆔 offers
+`,
+		},
+		{
+			name: "delete removes subtasks",
+			run: func(ctx context.Context, p *MarkdownTaskProvider) error {
+				return p.DeleteTask(ctx, "Home", "venue")
+			},
+			want: `## Home
+- [ ] Pay rent 🆔 rent
+`,
+		},
+		{
+			name: "unknown task",
+			run: func(ctx context.Context, p *MarkdownTaskProvider) error {
+				if _, err := p.GetTask(ctx, "Home", "nope"); !errors.Is(err, ErrTaskNotFound) {
+					return fmt.Errorf("GetTask error = %v, want ErrTaskNotFound", err)
+				}
+				return nil
+			},
+			want: initial,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.md")
+			if err := os.WriteFile(path, []byte(initial), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			p := NewMarkdownTaskProvider(path)
+			if err := tt.run(context.Background(), p); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(data)
+			got = generatedID.ReplaceAllString(got, "🆔 <id>")
+			got = strings.ReplaceAll(got, today(context.Background()).Format(time.DateOnly), "<today>")
+			if got != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDueDay(t *testing.T) {
+	brisbane, err := time.LoadLocation("Australia/Brisbane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := profile.NewContext(context.Background(), profile.Profile{TimeZone: "Australia/Brisbane"})
+	tests := []struct {
+		name string
+		due  time.Time
+		want string
+	}{
+		{"date only", time.Date(2025, 12, 11, 0, 0, 0, 0, time.UTC), "2025-12-11"},
+		{"morning in the user's zone is the previous day in UTC", time.Date(2025, 12, 11, 8, 0, 0, 0, brisbane), "2025-12-11"},
+		{"timed in UTC", time.Date(2025, 12, 10, 20, 0, 0, 0, time.UTC), "2025-12-11"},
+	}
+	for _, tt := range tests {
+		got := dueDay(ctx, tt.due)
+		if got.Format(time.DateOnly) != tt.want || got.Location() != time.UTC || !got.Equal(got.Truncate(24*time.Hour)) {
+			t.Errorf("%s: dueDay(%s) = %s, want %s at midnight UTC", tt.name, tt.due, got, tt.want)
+		}
+	}
+
+	now := time.Now().In(brisbane)
+	if got := today(ctx).Format(time.DateOnly); got != now.Format(time.DateOnly) {
+		t.Errorf("today() = %s, want the user's day %s", got, now.Format(time.DateOnly))
+	}
+}
@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// MoveTask reorders a task or changes its parent.
+type MoveTask struct {
+	provider TaskProvider
+}
+
+var _ tools.Tool = &MoveTask{}
+
+func NewMoveTask(provider TaskProvider) *MoveTask {
+	return &MoveTask{
+		provider: provider,
+	}
+}
+
+func (m *MoveTask) Name() string {
+	return "tasks_move"
+}
+
+func (m *MoveTask) Description() string {
+	return `Reorder a task or move it under another task as a subtask.
+
+Input must be a stringified JSON object like:
+{"task_id": "abc123", "parent": "def456", "previous": "ghi789", "task_list_id": "@default"}
+
+Fields:
+- task_id (string, required): task to move.
+- parent (string, optional): id of the new parent task. Omit to move the task to the top level; pass the current parent to reorder subtasks.
+- previous (string, optional): id of the sibling to place the task after. Omit to make it the first among its siblings.
+- task_list_id (string, optional): Task list id or name; omit for @default.`
+}
+
+func (m *MoveTask) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"task_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the task to move (required).",
+			},
+			"parent": map[string]interface{}{
+				"type":        "string",
+				"description": "New parent task id; omit for the top level.",
+			},
+			"previous": map[string]interface{}{
+				"type":        "string",
+				"description": "Sibling to place the task after; omit to put it first.",
+			},
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+		},
+		"required": []string{"task_id"},
+	}
+}
+
+func (m *MoveTask) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseMoveTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	moved, err := m.provider.MoveTask(ctx, payload.TaskListID, payload.TaskID, payload.Parent, payload.Previous)
+	if err != nil {
+		return "", err
+	}
+	if moved.Parent != "" {
+		return fmt.Sprintf("Moved task \"%s\" under %s.", moved.Title, moved.Parent), nil
+	}
+	return fmt.Sprintf("Moved task \"%s\".", moved.Title), nil
+}
+
+// Describe checks that the task exists and summarises the move.
+func (m *MoveTask) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseMoveTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+	existing, err := m.provider.GetTask(ctx, payload.TaskListID, payload.TaskID)
+	if err != nil {
+		return "", err
+	}
+
+	desc := fmt.Sprintf("Move task \"%s\"", existing.Title)
+	if payload.Parent != "" {
+		parent, err := m.provider.GetTask(ctx, payload.TaskListID, payload.Parent)
+		if err != nil {
+			return "", fmt.Errorf("unable to find parent task: %w", err)
+		}
+		desc += fmt.Sprintf(" under \"%s\"", parent.Title)
+	} else {
+		desc += " to the top level"
+	}
+	if payload.Previous != "" {
+		previous, err := m.provider.GetTask(ctx, payload.TaskListID, payload.Previous)
+		if err != nil {
+			return "", err
+		}
+		desc += fmt.Sprintf(" after \"%s\"", previous.Title)
+	} else {
+		desc += " as the first item"
+	}
+	return desc, nil
+}
+
+type moveTaskInput struct {
+	TaskID     string `json:"task_id"`
+	Parent     string `json:"parent,omitempty"`
+	Previous   string `json:"previous,omitempty"`
+	TaskListID string `json:"task_list_id,omitempty"`
+}
+
+func parseMoveTaskInput(raw string) (moveTaskInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return moveTaskInput{}, fmt.Errorf("provide the move as a JSON object in the tool input")
+	}
+
+	var payload moveTaskInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return moveTaskInput{}, fmt.Errorf("invalid move task payload; expected a JSON object: %w", err)
+	}
+
+	payload.TaskID = strings.TrimSpace(payload.TaskID)
+	payload.Parent = strings.TrimSpace(payload.Parent)
+	payload.Previous = strings.TrimSpace(payload.Previous)
+	if payload.TaskID == "" {
+		return moveTaskInput{}, fmt.Errorf("task_id is required to move a task")
+	}
+	if payload.Parent == payload.TaskID || payload.Previous == payload.TaskID {
+		return moveTaskInput{}, fmt.Errorf("a task can't be its own parent or predecessor")
+	}
+	return payload, nil
+}
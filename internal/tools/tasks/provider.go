@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TaskProvider is the storage backend behind ListTasks and AddTask. Every
+// call receives the request context so providers can resolve per-user
+// credentials from it.
+type TaskProvider interface {
+	// ListTasks returns the tasks matching q together with the title of
+	// their list.
+	ListTasks(ctx context.Context, q TaskQuery) ([]Task, string, error)
+	// InsertTask creates task in the list named by task.ListID (an id or
+	// name; empty selects the default list), below task.Parent when set and
+	// right after the sibling previous; an empty previous puts it first.
+	// A list that doesn't exist fails with ErrUnknownList.
+	InsertTask(ctx context.Context, task Task, previous string) (Task, error)
+	// GetTask returns the task id of the list listID.
+	GetTask(ctx context.Context, listID, id string) (Task, error)
+	// UpdateTask writes the title, notes, metadata, status and due date of
+	// task, found by task.ID in task.ListID.
+	UpdateTask(ctx context.Context, task Task) (Task, error)
+	// DeleteTask removes the task id and its subtasks.
+	DeleteTask(ctx context.Context, listID, id string) error
+	// MoveTask puts the task id below parent (empty for the top level),
+	// right after the sibling previous; an empty previous puts it first.
+	MoveTask(ctx context.Context, listID, id, parent, previous string) (Task, error)
+	// CreateList creates an empty task list and returns its id.
+	CreateList(ctx context.Context, title string) (string, error)
+}
+
+var (
+	// ErrUnknownList is returned for a task list id or name that matches
+	// no list.
+	ErrUnknownList = errors.New("unknown task list")
+	// ErrTaskNotFound is returned for a task that doesn't exist or was
+	// deleted.
+	ErrTaskNotFound = errors.New("task not found")
+)
+
+// NewProviderFromEnv builds the provider selected by TASKS_PROVIDER
+// ("google" or "markdown"). Without a choice Google Tasks is used when
+// credentials are configured and the markdown file otherwise, so tasks work
+// without any cloud account. The markdown file is TASKS_FILE inside
+// notesDir, tasks.md by default.
+func NewProviderFromEnv(credFile string, oauthEnabled bool, notesDir string) (TaskProvider, error) {
+	kind := strings.ToLower(strings.TrimSpace(os.Getenv("TASKS_PROVIDER")))
+	if kind == "" {
+		kind = "markdown"
+		if credFile != "" || oauthEnabled {
+			kind = "google"
+		}
+	}
+
+	switch kind {
+	case "google":
+		if credFile == "" && !oauthEnabled {
+			return nil, fmt.Errorf("TASKS_PROVIDER=google needs -with-creds-file or -with-creds-oauth")
+		}
+		return NewGoogleTaskProvider(credFile), nil
+	case "markdown", "local":
+		name := strings.TrimSpace(os.Getenv("TASKS_FILE"))
+		if name == "" {
+			name = "tasks.md"
+		}
+		return NewMarkdownTaskProvider(filepath.Join(notesDir, name)), nil
+	default:
+		return nil, fmt.Errorf("unknown TASKS_PROVIDER %q; use google or markdown", kind)
+	}
+}
@@ -0,0 +1,435 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"groundhog/internal/oplog"
+	"groundhog/internal/profile"
+	"groundhog/internal/recurring"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ListRecurring shows the user's recurring task rules.
+type ListRecurring struct {
+	store *recurring.Store
+}
+
+var _ tools.Tool = &ListRecurring{}
+
+func NewListRecurring(store *recurring.Store) *ListRecurring {
+	return &ListRecurring{
+		store: store,
+	}
+}
+
+func (l *ListRecurring) Name() string {
+	return "tasks_recurring_list"
+}
+
+func (l *ListRecurring) Description() string {
+	return `List the user's recurring tasks with their schedule, the due date of the current instance and their ids.`
+}
+
+func (l *ListRecurring) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+		"required":   []string{},
+	}
+}
+
+func (l *ListRecurring) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	rules := l.store.List(userID(ctx))
+	if len(rules) == 0 {
+		return "No recurring tasks.", nil
+	}
+	var b strings.Builder
+	b.WriteString("Recurring tasks:\n")
+	for _, r := range rules {
+		fmt.Fprintf(&b, "- %s | %s", r.Title, r.Describe())
+		if r.Due != "" {
+			fmt.Fprintf(&b, " | current due: %s", r.Due)
+		}
+		fmt.Fprintf(&b, " | id: %s\n", r.ID)
+	}
+	return b.String(), nil
+}
+
+// ManageRecurring creates and deletes recurring task rules. Creating a rule
+// also creates its first task; RecurringSync creates the following ones.
+type ManageRecurring struct {
+	provider TaskProvider
+	store    *recurring.Store
+}
+
+var _ tools.Tool = &ManageRecurring{}
+
+func NewManageRecurring(provider TaskProvider, store *recurring.Store) *ManageRecurring {
+	return &ManageRecurring{
+		provider: provider,
+		store:    store,
+	}
+}
+
+func (m *ManageRecurring) Name() string {
+	return "tasks_recurring"
+}
+
+func (m *ManageRecurring) Description() string {
+	return `Create or delete a recurring task. Google Tasks has no recurrence, so GroundHog keeps the schedule and creates the next task when the current one is completed or when its next date arrives.
+
+Input must be a stringified JSON object like:
+{"action": "create", "title": "Weekly review", "frequency": "weekly", "weekdays": ["friday"], "start": "2025-12-12"}
+{"action": "create", "title": "Pay rent", "frequency": "monthly", "start": "2026-01-01"}
+{"action": "delete", "id": "a1b2c3"}
+
+Fields:
+- action (string, required): create or delete.
+- title (string, required for create)
+- notes (string, optional): copied to every task.
+- frequency (string, required for create): daily, weekly, monthly or yearly.
+- interval (integer, optional): repeat every n periods, e.g. 2 for every other week. Defaults to 1.
+- weekdays (array of strings, optional): days of a weekly rule; defaults to the weekday of start.
+- start (string, optional): first date as YYYY-MM-DD or a relative expression; defaults to today. Monthly and yearly rules repeat on its day.
+- task_list_id (string, optional): Task list id or name; omit for @default.
+- id (string, required for delete): id from tasks_recurring_list. Deleting a rule keeps the tasks already created.`
+}
+
+func (m *ManageRecurring) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"create", "delete"},
+				"description": "create or delete (required).",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Task title (required for create).",
+			},
+			"notes": map[string]interface{}{
+				"type":        "string",
+				"description": "Notes copied to every task.",
+			},
+			"frequency": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{recurring.Daily, recurring.Weekly, recurring.Monthly, recurring.Yearly},
+				"description": "How often the task repeats (required for create).",
+			},
+			"interval": map[string]interface{}{
+				"type":        "integer",
+				"description": "Repeat every n periods; defaults to 1.",
+			},
+			"weekdays": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Weekdays of a weekly rule, e.g. [\"monday\", \"thursday\"].",
+			},
+			"start": map[string]interface{}{
+				"type":        "string",
+				"description": "First date, YYYY-MM-DD or relative; defaults to today.",
+			},
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "Id of the rule to delete.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (m *ManageRecurring) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseManageRecurringInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	if payload.Action == "delete" {
+		deleted, err := m.store.Delete(userID(ctx), payload.ID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Deleted recurring task \"%s\"; existing tasks were kept.", deleted.Title), nil
+	}
+
+	rule, err := payload.rule(profile.Location(ctx))
+	if err != nil {
+		return "", err
+	}
+	created, err := createInstance(ctx, m.provider, rule, rule.First())
+	if err != nil {
+		return "", err
+	}
+	rule.TaskListID, rule.TaskID, rule.Due = created.ListID, created.ID, rule.First().Format(time.DateOnly)
+	if rule, err = m.store.Save(userID(ctx), rule); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Created recurring task \"%s\" (%s, id: %s); the first task is due %s.", rule.Title, rule.Describe(), rule.ID, rule.Due), nil
+}
+
+// Describe validates input and summarises the rule to create or delete.
+func (m *ManageRecurring) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseManageRecurringInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	if payload.Action == "delete" {
+		for _, r := range m.store.List(userID(ctx)) {
+			if r.ID == payload.ID {
+				return fmt.Sprintf("Delete recurring task \"%s\" (%s)", r.Title, r.Describe()), nil
+			}
+		}
+		return "", fmt.Errorf("no recurring task with id %q", payload.ID)
+	}
+
+	rule, err := payload.rule(profile.Location(ctx))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Create recurring task \"%s\" %s, first due %s", rule.Title, rule.Describe(), rule.First().Format(time.DateOnly)), nil
+}
+
+// Resolve fixes the start day of a new rule, which defaults to today.
+func (m *ManageRecurring) Resolve(ctx context.Context, input string) (string, error) {
+	payload, err := parseManageRecurringInput(input)
+	if err != nil || payload.Action != "create" {
+		return input, err
+	}
+	rule, err := payload.rule(profile.Location(ensureContext(ctx)))
+	if err != nil {
+		return "", err
+	}
+	payload.Start = rule.Start
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
+}
+
+type manageRecurringInput struct {
+	Action     string   `json:"action"`
+	ID         string   `json:"id,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	Frequency  string   `json:"frequency,omitempty"`
+	Interval   int      `json:"interval,omitempty"`
+	Weekdays   []string `json:"weekdays,omitempty"`
+	Start      string   `json:"start,omitempty"`
+	TaskListID string   `json:"task_list_id,omitempty"`
+}
+
+func parseManageRecurringInput(raw string) (manageRecurringInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return manageRecurringInput{}, fmt.Errorf("provide the recurring task as a JSON object in the tool input")
+	}
+
+	var payload manageRecurringInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return manageRecurringInput{}, fmt.Errorf("invalid recurring task payload; expected a JSON object: %w", err)
+	}
+
+	payload.Action = strings.ToLower(strings.TrimSpace(payload.Action))
+	payload.ID = strings.TrimSpace(payload.ID)
+	switch payload.Action {
+	case "create":
+	case "delete":
+		if payload.ID == "" {
+			return manageRecurringInput{}, fmt.Errorf("id is required to delete a recurring task")
+		}
+	default:
+		return manageRecurringInput{}, fmt.Errorf("action must be create or delete")
+	}
+	return payload, nil
+}
+
+// rule builds the validated rule to create, resolving start in loc.
+func (in manageRecurringInput) rule(loc *time.Location) (recurring.Rule, error) {
+	start := time.Now().In(loc).Format(time.DateOnly)
+	if in.Start != "" {
+		due, err := normalizeDue(in.Start, loc)
+		if err != nil {
+			return recurring.Rule{}, fmt.Errorf("invalid start: %w", err)
+		}
+		t := parseTaskTime(due)
+		if !strings.HasSuffix(due, "T00:00:00Z") {
+			// Date-only values are midnight UTC; only real times move
+			// into the user's time zone.
+			t = t.In(loc)
+		}
+		start = t.Format(time.DateOnly)
+	}
+
+	r := recurring.Rule{
+		Title:      in.Title,
+		Notes:      strings.TrimSpace(in.Notes),
+		TaskListID: strings.TrimSpace(in.TaskListID),
+		Frequency:  in.Frequency,
+		Interval:   in.Interval,
+		Weekdays:   in.Weekdays,
+		Start:      start,
+	}
+	if err := r.Validate(); err != nil {
+		return recurring.Rule{}, err
+	}
+	return r, nil
+}
+
+// RecurringSync creates the due instances of the recurring task rules.
+type RecurringSync struct {
+	provider TaskProvider
+	store    *recurring.Store
+
+	mu sync.Mutex
+	// users serialises the runs of each user so overlapping runs can't
+	// both create the same instance.
+	users map[string]*sync.Mutex
+}
+
+func NewRecurringSync(provider TaskProvider, store *recurring.Store) *RecurringSync {
+	return &RecurringSync{
+		provider: provider,
+		store:    store,
+		users:    make(map[string]*sync.Mutex),
+	}
+}
+
+func (s *RecurringSync) lock(uid string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.users[uid]
+	if !ok {
+		l = &sync.Mutex{}
+		s.users[uid] = l
+	}
+	l.Lock()
+	return l
+}
+
+// Sync checks the current task of every rule of the user in ctx. When it is
+// completed or deleted, the next occurrence from today on is created. While
+// it is still open, a new task is created once the next occurrence's date
+// has arrived, so a missed instance doesn't hold back the routine. It
+// returns the number of tasks created.
+func (s *RecurringSync) Sync(ctx context.Context) (int, error) {
+	ctx = ensureContext(ctx)
+	uid := userID(ctx)
+	defer s.lock(uid).Unlock()
+	rules := s.store.List(uid)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	// Instances come from the rules, so undoing one is left to the user.
+	ctx = oplog.NewContext(ctx, nil)
+	now := time.Now().In(profile.Location(ctx))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	created := 0
+	for _, rule := range rules {
+		due, next, err := s.nextInstance(ctx, rule, today)
+		if err != nil {
+			return created, err
+		}
+		if next.IsZero() {
+			continue
+		}
+		if !next.After(today) || due.IsZero() {
+			// The rule is advanced before the insert, so a run that
+			// fails or stops in between can't create the instance twice.
+			rule.TaskID, rule.Due = "", next.Format(time.DateOnly)
+			if rule, err = s.store.Save(uid, rule); err != nil {
+				return created, err
+			}
+			task, err := createInstance(ctx, s.provider, rule, next)
+			if err != nil {
+				return created, err
+			}
+			rule.TaskID = task.ID
+			if _, err := s.store.Save(uid, rule); err != nil {
+				return created, err
+			}
+			created++
+		}
+	}
+	return created, nil
+}
+
+// nextInstance returns the due date of the rule's current task, zero when it
+// is done, and the date of the task to create next.
+func (s *RecurringSync) nextInstance(ctx context.Context, rule recurring.Rule, today time.Time) (time.Time, time.Time, error) {
+	due, err := time.Parse(time.DateOnly, rule.Due)
+	if err != nil {
+		return time.Time{}, rule.First(), nil
+	}
+	if rule.TaskID == "" {
+		// Advanced to due, but its task wasn't created.
+		return time.Time{}, due, nil
+	}
+
+	done := false
+	task, err := s.provider.GetTask(ctx, rule.TaskListID, rule.TaskID)
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		done = true
+	case err != nil:
+		return time.Time{}, time.Time{}, fmt.Errorf("unable to fetch task %q: %w", rule.TaskID, err)
+	default:
+		done = task.Status == "completed"
+	}
+
+	next := rule.Next(due)
+	if done {
+		// Skip the occurrences that passed while the task stayed open.
+		for !next.IsZero() && next.Before(today) {
+			next = rule.Next(next)
+		}
+		return time.Time{}, next, nil
+	}
+	for following := rule.Next(next); !following.IsZero() && !following.After(today); following = rule.Next(following) {
+		next = following
+	}
+	return due, next, nil
+}
+
+func createInstance(ctx context.Context, provider TaskProvider, rule recurring.Rule, due time.Time) (Task, error) {
+	task := Task{
+		ListID: rule.TaskListID,
+		Title:  rule.Title,
+		Notes:  rule.Notes,
+		Status: "needsAction",
+		Due:    due,
+	}
+	created, err := provider.InsertTask(ctx, task, "")
+	if err != nil {
+		return Task{}, fmt.Errorf("unable to create task \"%s\": %w", rule.Title, err)
+	}
+	return created, nil
+}
+
+func userID(ctx context.Context) string {
+	id, _ := ctx.Value("UserID").(string)
+	return id
+}
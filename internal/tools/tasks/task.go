@@ -0,0 +1,170 @@
+package tasks
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"groundhog/internal/taskmeta"
+
+	gtasks "google.golang.org/api/tasks/v1"
+)
+
+const defaultTaskList = "@default"
+
+// Task is a task independent of how the tools present it.
+type Task struct {
+	ID     string
+	ListID string
+	Title  string
+	// Notes is the free-form part of the notes; the metadata block is
+	// parsed into Meta.
+	Notes     string
+	Meta      taskmeta.Meta
+	Status    string
+	Due       time.Time
+	Completed time.Time
+	Updated   time.Time
+	Parent    string
+	Position  string
+	WebLink   string
+}
+
+// TaskQuery selects the tasks returned by Fetch.
+type TaskQuery struct {
+	// TaskListID is a list id or name; empty selects the default list.
+	TaskListID       string
+	IncludeCompleted bool
+	// Status keeps only needsAction or completed tasks when set. Completed
+	// tasks are included whenever it asks for them.
+	Status string
+	// DueMin and DueMax bound the due date when set; DueMax is exclusive.
+	DueMin time.Time
+	DueMax time.Time
+	// CompletedMin and CompletedMax bound the completion time when set and
+	// select completed tasks only; CompletedMax is exclusive.
+	CompletedMin time.Time
+	CompletedMax time.Time
+	// Query keeps tasks whose title or notes contain it, ignoring case.
+	Query string
+	// MaxResults caps the number of tasks returned; 0 returns all.
+	MaxResults int
+}
+
+// Fetch returns the tasks matching q together with the title of their list.
+func (l *ListTasks) Fetch(ctx context.Context, q TaskQuery) ([]Task, string, error) {
+	return l.provider.ListTasks(ctx, q)
+}
+
+// completed reports whether q asks for completed tasks.
+func (q TaskQuery) completed() bool {
+	return q.IncludeCompleted || q.Status == "completed" || !q.CompletedMin.IsZero() || !q.CompletedMax.IsZero()
+}
+
+// matches applies the filters of q to t. Providers that filter on the
+// server still run it so every backend answers the same query alike.
+func (q TaskQuery) matches(t Task) bool {
+	if !q.completed() && t.Status == "completed" {
+		return false
+	}
+	if q.Status != "" && t.Status != q.Status {
+		return false
+	}
+	if !inRange(t.Due, q.DueMin, q.DueMax) {
+		return false
+	}
+	if (!q.CompletedMin.IsZero() || !q.CompletedMax.IsZero()) && (t.Status != "completed" || !inRange(t.Completed, q.CompletedMin, q.CompletedMax)) {
+		return false
+	}
+	if query := strings.ToLower(strings.TrimSpace(q.Query)); query != "" {
+		return strings.Contains(strings.ToLower(t.Title), query) || strings.Contains(strings.ToLower(t.Notes), query)
+	}
+	return true
+}
+
+// full reports whether n tasks reach MaxResults.
+func (q TaskQuery) full(n int) bool {
+	return q.MaxResults > 0 && n >= q.MaxResults
+}
+
+// inRange reports whether t lies in [from, to); unset bounds are open but
+// an unset t never matches a set bound.
+func inRange(t, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// nestedTask is a task with its depth below the top level.
+type nestedTask struct {
+	Task
+	Depth int
+}
+
+// nest orders tasks as Google Tasks shows them: siblings by position and
+// subtasks right below their parent. Subtasks whose parent isn't in items
+// are kept at the top level.
+func nest(items []Task) []nestedTask {
+	present := make(map[string]bool, len(items))
+	for _, t := range items {
+		present[t.ID] = true
+	}
+	children := make(map[string][]Task)
+	for _, t := range items {
+		parent := t.Parent
+		if !present[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], t)
+	}
+
+	res := make([]nestedTask, 0, len(items))
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		group := children[parent]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Position < group[j].Position })
+		for _, t := range group {
+			res = append(res, nestedTask{Task: t, Depth: depth})
+			walk(t.ID, depth+1)
+		}
+	}
+	walk("", 0)
+	return res
+}
+
+func fromGoogleTask(listID string, item *gtasks.Task) Task {
+	t := Task{
+		ID:       item.Id,
+		ListID:   listID,
+		Title:    strings.TrimSpace(item.Title),
+		Status:   strings.TrimSpace(item.Status),
+		Parent:   item.Parent,
+		Position: item.Position,
+		WebLink:  item.WebViewLink,
+	}
+	if t.Status == "" {
+		t.Status = "needsAction"
+	}
+	t.Notes, t.Meta = taskmeta.Parse(item.Notes)
+	t.Due = parseTaskTime(item.Due)
+	if item.Completed != nil {
+		t.Completed = parseTaskTime(*item.Completed)
+	}
+	t.Updated = parseTaskTime(item.Updated)
+	return t
+}
+
+// parseTaskTime returns the zero time for empty or malformed values; Google
+// only ever sends RFC3339 here.
+func parseTaskTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
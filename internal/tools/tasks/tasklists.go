@@ -0,0 +1,268 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gtasks "google.golang.org/api/tasks/v1"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ListTaskLists shows the user's Google Tasks lists.
+type ListTaskLists struct {
+	credFile string
+}
+
+var _ tools.Tool = &ListTaskLists{}
+
+func NewListTaskLists(credFile string) *ListTaskLists {
+	return &ListTaskLists{
+		credFile: credFile,
+	}
+}
+
+func (l *ListTaskLists) Name() string {
+	return "tasks_lists"
+}
+
+func (l *ListTaskLists) Description() string {
+	return `List the user's Google Tasks lists with their ids. The other tasks tools accept either the id or the name of a list.`
+}
+
+func (l *ListTaskLists) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+		"required":   []string{},
+	}
+}
+
+func (l *ListTaskLists) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	srv, err := newTasksService(ctx, l.credFile)
+	if err != nil {
+		return "", err
+	}
+	lists, err := allTaskLists(ctx, srv)
+	if err != nil {
+		return "", err
+	}
+	if len(lists) == 0 {
+		return "No task lists found.", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Task lists:\n")
+	for i, tl := range lists {
+		fmt.Fprintf(&b, "- %s | id: %s", tl.Title, tl.Id)
+		if i == 0 {
+			// Google returns the default list first.
+			b.WriteString(" | default")
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// ManageTaskList creates or renames a Google Tasks list.
+type ManageTaskList struct {
+	credFile string
+}
+
+var _ tools.Tool = &ManageTaskList{}
+
+func NewManageTaskList(credFile string) *ManageTaskList {
+	return &ManageTaskList{
+		credFile: credFile,
+	}
+}
+
+func (m *ManageTaskList) Name() string {
+	return "tasks_manage_list"
+}
+
+func (m *ManageTaskList) Description() string {
+	return `Create or rename a Google Tasks list.
+
+Input must be a stringified JSON object like:
+{"action": "create", "title": "Groceries"}
+{"action": "rename", "task_list_id": "Groceries", "title": "Shopping"}
+
+Fields:
+- action (string, required): create or rename.
+- title (string, required): name of the new list, or the new name when renaming.
+- task_list_id (string, required for rename): id or current name of the list.`
+}
+
+func (m *ManageTaskList) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"create", "rename"},
+				"description": "create or rename (required).",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the new list, or the new name (required).",
+			},
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Id or current name of the list to rename.",
+			},
+		},
+		"required": []string{"action", "title"},
+	}
+}
+
+func (m *ManageTaskList) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseManageTaskListInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	srv, err := newTasksService(ctx, m.credFile)
+	if err != nil {
+		return "", err
+	}
+
+	if payload.Action == "create" {
+		created, err := srv.Tasklists.Insert(&gtasks.TaskList{Title: payload.Title}).Context(ctx).Do()
+		if err != nil {
+			return "", fmt.Errorf("unable to create task list: %w", err)
+		}
+		recordTaskListChange(ctx, nil, created)
+		return fmt.Sprintf("Created task list \"%s\" (id: %s)", created.Title, created.Id), nil
+	}
+
+	listID, _, err := resolveTaskList(ctx, srv, payload.TaskListID)
+	if err != nil {
+		return "", err
+	}
+	existing, err := srv.Tasklists.Get(listID).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch task list %q: %w", payload.TaskListID, err)
+	}
+	saved, err := srv.Tasklists.Patch(listID, &gtasks.TaskList{Title: payload.Title}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to rename task list: %w", err)
+	}
+	recordTaskListChange(ctx, existing, saved)
+	return fmt.Sprintf("Renamed task list \"%s\" to \"%s\" (id: %s)", existing.Title, saved.Title, saved.Id), nil
+}
+
+// Describe validates input and, for renames, checks that the list exists.
+func (m *ManageTaskList) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseManageTaskListInput(input)
+	if err != nil {
+		return "", err
+	}
+	if payload.Action == "create" {
+		return fmt.Sprintf("Create task list \"%s\"", payload.Title), nil
+	}
+
+	srv, err := newTasksService(ctx, m.credFile)
+	if err != nil {
+		return "", err
+	}
+	_, title, err := resolveTaskList(ctx, srv, payload.TaskListID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Rename task list \"%s\" to \"%s\"", title, payload.Title), nil
+}
+
+type manageTaskListInput struct {
+	Action     string `json:"action"`
+	Title      string `json:"title"`
+	TaskListID string `json:"task_list_id,omitempty"`
+}
+
+func parseManageTaskListInput(raw string) (manageTaskListInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return manageTaskListInput{}, fmt.Errorf("provide the list details as a JSON object in the tool input")
+	}
+
+	var payload manageTaskListInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return manageTaskListInput{}, fmt.Errorf("invalid task list payload; expected a JSON object: %w", err)
+	}
+
+	payload.Action = strings.ToLower(strings.TrimSpace(payload.Action))
+	payload.Title = strings.TrimSpace(payload.Title)
+	payload.TaskListID = strings.TrimSpace(payload.TaskListID)
+	if payload.Title == "" {
+		return manageTaskListInput{}, fmt.Errorf("title is required")
+	}
+	switch payload.Action {
+	case "create":
+	case "rename":
+		if payload.TaskListID == "" {
+			return manageTaskListInput{}, fmt.Errorf("task_list_id is required to rename a list")
+		}
+	default:
+		return manageTaskListInput{}, fmt.Errorf("action must be create or rename")
+	}
+	return payload, nil
+}
+
+// resolveTaskList turns a list id or name into the list id and title. An
+// empty value selects the default list.
+func resolveTaskList(ctx context.Context, srv *gtasks.Service, value string) (string, string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == defaultTaskList {
+		title := defaultTaskList
+		if tl, err := srv.Tasklists.Get(defaultTaskList).Context(ctx).Do(); err == nil && strings.TrimSpace(tl.Title) != "" {
+			title = tl.Title
+		}
+		return defaultTaskList, title, nil
+	}
+
+	lists, err := allTaskLists(ctx, srv)
+	if err != nil {
+		return "", "", err
+	}
+	for _, tl := range lists {
+		if tl.Id == value {
+			return tl.Id, tl.Title, nil
+		}
+	}
+	for _, tl := range lists {
+		if strings.EqualFold(strings.TrimSpace(tl.Title), value) {
+			return tl.Id, tl.Title, nil
+		}
+	}
+
+	names := make([]string, 0, len(lists))
+	for _, tl := range lists {
+		names = append(names, fmt.Sprintf("%q", tl.Title))
+	}
+	return "", "", fmt.Errorf("%w %q; available lists: %s", ErrUnknownList, value, strings.Join(names, ", "))
+}
+
+func allTaskLists(ctx context.Context, srv *gtasks.Service) ([]*gtasks.TaskList, error) {
+	var res []*gtasks.TaskList
+	err := srv.Tasklists.List().MaxResults(100).Pages(ctx, func(page *gtasks.TaskLists) error {
+		res = append(res, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve task lists: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,359 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"groundhog/internal/oplog"
+
+	gtasks "google.golang.org/api/tasks/v1"
+)
+
+const (
+	opCreateTask = "tasks.create"
+	opUpdateTask = "tasks.update"
+	opDeleteTask = "tasks.delete"
+	opCreateList = "tasks.list_create"
+	opRenameList = "tasks.list_rename"
+	opMoveTask   = "tasks.move"
+
+	opCreateMarkdownTask = "markdown.create"
+	opUpdateMarkdownTask = "markdown.update"
+	opDeleteMarkdownTask = "markdown.delete"
+	opMoveMarkdownTask   = "markdown.move"
+	opCreateMarkdownList = "markdown.list_create"
+)
+
+// loggedTask is what the operation log keeps for a task.
+type loggedTask struct {
+	ListID string       `json:"list_id"`
+	Task   *gtasks.Task `json:"task"`
+}
+
+// recordTaskChange logs a task mutation so it can be undone. before is nil for
+// new tasks. Logging failures don't fail the tool because the task list has
+// already changed.
+func recordTaskChange(ctx context.Context, listID string, before, after *gtasks.Task) {
+	kind, summary := opUpdateTask, fmt.Sprintf("Update task %q", after.Title)
+	var beforeEntry any
+	if before == nil {
+		kind, summary = opCreateTask, fmt.Sprintf("Create task %q", after.Title)
+	} else {
+		beforeEntry = loggedTask{ListID: listID, Task: before}
+	}
+	if err := oplog.Record(ctx, kind, after.Id, summary, beforeEntry, loggedTask{ListID: listID, Task: after}); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+}
+
+// recordTaskDelete logs a deleted task so it can be recreated.
+func recordTaskDelete(ctx context.Context, listID string, before *gtasks.Task) {
+	if err := oplog.Record(ctx, opDeleteTask, before.Id, fmt.Sprintf("Delete task %q", before.Title), loggedTask{ListID: listID, Task: before}, nil); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+}
+
+// loggedMove is the place of a task among its siblings.
+type loggedMove struct {
+	ListID   string `json:"list_id"`
+	Parent   string `json:"parent,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+func recordTaskMove(ctx context.Context, moved *gtasks.Task, before, after loggedMove) {
+	if err := oplog.Record(ctx, opMoveTask, moved.Id, fmt.Sprintf("Move task %q", moved.Title), before, after); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+}
+
+// recordTaskListChange logs a created (before is nil) or renamed task list.
+func recordTaskListChange(ctx context.Context, before, after *gtasks.TaskList) {
+	kind, summary := opCreateList, fmt.Sprintf("Create task list %q", after.Title)
+	var beforeEntry any
+	if before != nil {
+		kind, summary = opRenameList, fmt.Sprintf("Rename task list %q to %q", before.Title, after.Title)
+		beforeEntry = before
+	}
+	if err := oplog.Record(ctx, kind, after.Id, summary, beforeEntry, after); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+}
+
+// Reverter undoes logged task operations.
+type Reverter struct {
+	credFile string
+}
+
+var _ oplog.Reverter = &Reverter{}
+
+func NewReverter(credFile string) *Reverter {
+	return &Reverter{
+		credFile: credFile,
+	}
+}
+
+func (r *Reverter) Revert(ctx context.Context, op oplog.Operation) error {
+	srv, err := newTasksService(ctx, r.credFile)
+	if err != nil {
+		return err
+	}
+
+	switch op.Kind {
+	case opCreateList:
+		tasks, err := srv.Tasks.List(op.Target).ShowCompleted(true).ShowHidden(true).MaxResults(1).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to retrieve tasks: %w", err)
+		}
+		if len(tasks.Items) > 0 {
+			return fmt.Errorf("the task list has tasks now; delete it directly instead")
+		}
+		if err := srv.Tasklists.Delete(op.Target).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("unable to delete task list: %w", err)
+		}
+		return nil
+	case opRenameList:
+		var before, after gtasks.TaskList
+		if err := json.Unmarshal(op.Before, &before); err != nil {
+			return fmt.Errorf("invalid logged task list")
+		}
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged task list")
+		}
+		current, err := srv.Tasklists.Get(op.Target).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to fetch task list: %w", err)
+		}
+		if current.Title != after.Title {
+			return fmt.Errorf("task list %q was renamed again since; rename it directly instead", current.Title)
+		}
+		if _, err := srv.Tasklists.Patch(op.Target, &gtasks.TaskList{Title: before.Title}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("unable to rename task list: %w", err)
+		}
+		return nil
+	case opMoveTask:
+		var before, after loggedMove
+		if err := json.Unmarshal(op.Before, &before); err != nil {
+			return fmt.Errorf("invalid logged move")
+		}
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged move")
+		}
+		current, err := srv.Tasks.Get(after.ListID, op.Target).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to fetch task: %w", err)
+		}
+		if current.Parent != after.Parent {
+			return fmt.Errorf("task %q was moved again since; move it directly instead", current.Title)
+		}
+		call := srv.Tasks.Move(before.ListID, op.Target).Context(ctx)
+		if before.Parent != "" {
+			call = call.Parent(before.Parent)
+		}
+		if before.Previous != "" {
+			call = call.Previous(before.Previous)
+		}
+		if _, err := call.Do(); err != nil {
+			return fmt.Errorf("unable to move task back: %w", err)
+		}
+		return nil
+	case opDeleteTask:
+		var before loggedTask
+		if err := json.Unmarshal(op.Before, &before); err != nil || before.Task == nil {
+			return fmt.Errorf("invalid logged task")
+		}
+		// The task comes back under a new id.
+		restore := &gtasks.Task{
+			Title:  before.Task.Title,
+			Notes:  before.Task.Notes,
+			Status: before.Task.Status,
+			Due:    before.Task.Due,
+		}
+		call := srv.Tasks.Insert(before.ListID, restore).Context(ctx)
+		if before.Task.Parent != "" {
+			call = call.Parent(before.Task.Parent)
+		}
+		if _, err := call.Do(); err != nil {
+			return fmt.Errorf("unable to restore task: %w", err)
+		}
+		return nil
+	}
+
+	var after loggedTask
+	if err := json.Unmarshal(op.After, &after); err != nil || after.Task == nil {
+		return fmt.Errorf("invalid logged task")
+	}
+
+	switch op.Kind {
+	case opCreateTask:
+		current, err := srv.Tasks.Get(after.ListID, op.Target).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to fetch task: %w", err)
+		}
+		if current.Updated != after.Task.Updated {
+			return fmt.Errorf("task %q was changed since it was created; delete it directly instead", current.Title)
+		}
+		if err := srv.Tasks.Delete(after.ListID, op.Target).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("unable to delete task: %w", err)
+		}
+		return nil
+	case opUpdateTask:
+		var before loggedTask
+		if err := json.Unmarshal(op.Before, &before); err != nil || before.Task == nil {
+			return fmt.Errorf("invalid logged task")
+		}
+		current, err := srv.Tasks.Get(after.ListID, op.Target).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to fetch task: %w", err)
+		}
+		if current.Updated != after.Task.Updated {
+			return fmt.Errorf("task %q was changed again since; edit it directly instead", current.Title)
+		}
+		restore := &gtasks.Task{
+			Title:           before.Task.Title,
+			Notes:           before.Task.Notes,
+			Status:          before.Task.Status,
+			Due:             before.Task.Due,
+			ForceSendFields: []string{"Title", "Notes", "Status", "Due"},
+		}
+		if before.Task.Status != "completed" {
+			restore.NullFields = []string{"Completed"}
+		}
+		if before.Task.Due == "" {
+			restore.ForceSendFields = []string{"Title", "Notes", "Status"}
+			restore.NullFields = append(restore.NullFields, "Due")
+		}
+		if _, err := srv.Tasks.Patch(after.ListID, op.Target, restore).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("unable to restore task: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown task operation %q", op.Kind)
+	}
+}
+
+// markdownBlock is a deleted markdown task: its lines, with notes and
+// subtasks, and where they stood.
+type markdownBlock struct {
+	loggedMove
+	Lines []string `json:"lines"`
+}
+
+// recordMarkdownTask logs a created (before is nil) or updated markdown task.
+func recordMarkdownTask(ctx context.Context, kind, id, summary string, before, after *Task) {
+	var beforeEntry any
+	if before != nil {
+		beforeEntry = before
+	}
+	if err := oplog.Record(ctx, kind, id, summary, beforeEntry, after); err != nil {
+		log.Println("Couldn't record task change:", err)
+	}
+}
+
+// MarkdownReverter undoes logged changes to a markdown task file.
+type MarkdownReverter struct {
+	provider *MarkdownTaskProvider
+}
+
+var _ oplog.Reverter = &MarkdownReverter{}
+
+func NewMarkdownReverter(provider *MarkdownTaskProvider) *MarkdownReverter {
+	return &MarkdownReverter{
+		provider: provider,
+	}
+}
+
+func (r *MarkdownReverter) Revert(ctx context.Context, op oplog.Operation) error {
+	m := r.provider
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	switch op.Kind {
+	case opCreateMarkdownList:
+		l, err := f.list(op.Target)
+		if err != nil || l.heading < 0 {
+			return fmt.Errorf("task list %q is gone", op.Target)
+		}
+		if len(l.tasks) > 0 {
+			return fmt.Errorf("the task list has tasks now; delete it directly instead")
+		}
+		start := l.heading
+		if start > 0 && strings.TrimSpace(f.lines[start-1]) == "" {
+			start--
+		}
+		f.lines = append(f.lines[:start], f.lines[l.end:]...)
+	case opDeleteMarkdownTask:
+		var before markdownBlock
+		if err := json.Unmarshal(op.Before, &before); err != nil || len(before.Lines) == 0 {
+			return fmt.Errorf("invalid logged task")
+		}
+		if _, _, err := f.find(before.ListID, op.Target); err == nil {
+			return fmt.Errorf("the task is back already")
+		}
+		l, err := f.list(before.ListID)
+		if errors.Is(err, ErrUnknownList) {
+			l = f.addList(before.ListID)
+		}
+		// The parent or sibling may be gone too; then the task goes first
+		// in its list.
+		at, indent, err := f.placement(l, before.Parent, before.Previous)
+		if err != nil {
+			at, indent, _ = f.placement(l, "", "")
+		}
+		f.insert(at, reindent(before.Lines, indent))
+	case opMoveMarkdownTask:
+		var before, after loggedMove
+		if err := json.Unmarshal(op.Before, &before); err != nil {
+			return fmt.Errorf("invalid logged move")
+		}
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged move")
+		}
+		l, t, err := f.find(after.ListID, op.Target)
+		if err != nil {
+			return err
+		}
+		if t.Parent != after.Parent {
+			return fmt.Errorf("task %q was moved again since; move it directly instead", t.Title)
+		}
+		if _, err := f.move(l, t, before.Parent, before.Previous); err != nil {
+			return fmt.Errorf("unable to move task back: %w", err)
+		}
+	case opCreateMarkdownTask, opUpdateMarkdownTask:
+		var after Task
+		if err := json.Unmarshal(op.After, &after); err != nil {
+			return fmt.Errorf("invalid logged task")
+		}
+		_, t, err := f.find(after.ListID, op.Target)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(formatMarkdownTask(t.Task, ""), formatMarkdownTask(after, "")) {
+			return fmt.Errorf("task %q was changed again since; edit it directly instead", t.Title)
+		}
+		if op.Kind == opCreateMarkdownTask {
+			if f.subtreeEnd(t) > f.notesEnd(t) {
+				return fmt.Errorf("task %q has subtasks now; delete it directly instead", t.Title)
+			}
+			f.lines = append(f.lines[:t.line], f.lines[f.notesEnd(t):]...)
+			break
+		}
+		var before Task
+		if err := json.Unmarshal(op.Before, &before); err != nil {
+			return fmt.Errorf("invalid logged task")
+		}
+		f.rewrite(t, before)
+	default:
+		return fmt.Errorf("unknown task operation %q", op.Kind)
+	}
+	return m.save(f)
+}
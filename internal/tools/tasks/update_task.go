@@ -0,0 +1,295 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"groundhog/internal/profile"
+	"groundhog/internal/taskmeta"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// UpdateTask edits, completes or reopens an existing task.
+type UpdateTask struct {
+	provider TaskProvider
+}
+
+var _ tools.Tool = &UpdateTask{}
+
+func NewUpdateTask(provider TaskProvider) *UpdateTask {
+	return &UpdateTask{
+		provider: provider,
+	}
+}
+
+func (u *UpdateTask) Name() string {
+	return "tasks_update"
+}
+
+func (u *UpdateTask) Description() string {
+	return `Update an existing task: rename it, change its notes or due date, mark it done or reopen it.
+
+Input must be a stringified JSON object like:
+{
+  "task_id": "abc123",
+  "title": "Buy groceries and flowers",
+  "notes": "Milk, eggs, bread",
+  "due": "2025-12-11",
+  "status": "completed",
+  "estimate_minutes": 45,
+  "task_list_id": "@default"
+}
+
+Fields:
+- task_id (string, required): id returned by the tasks listing.
+- title (string, optional)
+- notes (string, optional): replaces the notes; "" clears them. Metadata is kept.
+- due (string, optional): RFC3339 timestamp, YYYY-MM-DD, or a relative expression like "next Friday"; "" removes the due date.
+- status (string, optional): completed to mark the task done, needsAction to reopen it.
+- estimate_minutes (integer, optional): estimated minutes; 0 clears it.
+- priority (integer, optional): 1 is the most important; 0 clears it.
+- energy (string, optional): low, medium or high; "" clears it.
+- goal (string, optional): the larger goal; "" clears it.
+- task_list_id (string, optional): Task list id or name; omit for @default.`
+}
+
+func (u *UpdateTask) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"task_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the task to update (required).",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "New task title.",
+			},
+			"notes": map[string]interface{}{
+				"type":        "string",
+				"description": "New notes; an empty string clears them.",
+			},
+			"due": map[string]interface{}{
+				"type":        "string",
+				"description": "RFC3339 timestamp, YYYY-MM-DD or a relative expression; an empty string removes the due date.",
+			},
+			"status": map[string]interface{}{
+				"type":        "string",
+				"description": "completed to mark done, needsAction to reopen.",
+				"enum":        []string{"needsAction", "completed"},
+			},
+			"estimate_minutes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Estimated minutes of work; 0 clears it.",
+			},
+			"priority": map[string]interface{}{
+				"type":        "integer",
+				"description": "Priority, 1 is the most important; 0 clears it.",
+			},
+			"energy": map[string]interface{}{
+				"type":        "string",
+				"description": "low, medium or high; an empty string clears it.",
+			},
+			"goal": map[string]interface{}{
+				"type":        "string",
+				"description": "The larger goal; an empty string clears it.",
+			},
+			"task_list_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Task list id or name; omit to use the default list (@default).",
+			},
+		},
+		"required": []string{"task_id"},
+	}
+}
+
+func (u *UpdateTask) Call(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	payload, err := parseUpdateTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := u.provider.GetTask(ctx, payload.TaskListID, payload.TaskID)
+	if err != nil {
+		return "", err
+	}
+	updated, err := applyTaskUpdate(payload, existing, profile.Location(ctx))
+	if err != nil {
+		return "", err
+	}
+	t, err := u.provider.UpdateTask(ctx, updated)
+	if err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("Updated task \"%s\" (status: %s", t.Title, t.Status)
+	if !t.Due.IsZero() {
+		msg += fmt.Sprintf(", due: %s", t.Due.UTC().Format(time.DateOnly))
+	}
+	return msg + fmt.Sprintf(", id: %s)", t.ID), nil
+}
+
+// Describe validates input against the current task and lists the fields
+// that would change.
+func (u *UpdateTask) Describe(ctx context.Context, input string) (string, error) {
+	ctx = ensureContext(ctx)
+	payload, err := parseUpdateTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+	existing, err := u.provider.GetTask(ctx, payload.TaskListID, payload.TaskID)
+	if err != nil {
+		return "", err
+	}
+	updated, err := applyTaskUpdate(payload, existing, profile.Location(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	var diff []string
+	if payload.Title != nil {
+		diff = append(diff, fmt.Sprintf("title → %q", updated.Title))
+	}
+	if payload.Notes != nil {
+		diff = append(diff, "notes")
+	}
+	if payload.hasMeta() {
+		if summary := updated.Meta.Summary(); summary != "" {
+			diff = append(diff, summary)
+		} else {
+			diff = append(diff, "clear metadata")
+		}
+	}
+	if payload.Due != nil {
+		if updated.Due.IsZero() {
+			diff = append(diff, "remove due date")
+		} else {
+			diff = append(diff, fmt.Sprintf("due → %s", updated.Due.UTC().Format(time.DateOnly)))
+		}
+	}
+	switch payload.Status {
+	case "completed":
+		diff = append(diff, "mark done")
+	case "needsAction":
+		diff = append(diff, "reopen")
+	}
+	return fmt.Sprintf("Update task \"%s\": %s", existing.Title, strings.Join(diff, "; ")), nil
+}
+
+// Resolve makes a relative due date absolute.
+func (u *UpdateTask) Resolve(ctx context.Context, input string) (string, error) {
+	payload, err := parseUpdateTaskInput(input)
+	if err != nil {
+		return "", err
+	}
+	if payload.Due != nil {
+		due, err := normalizeDue(*payload.Due, profile.Location(ensureContext(ctx)))
+		if err != nil {
+			return "", fmt.Errorf("invalid due: %w", err)
+		}
+		payload.Due = &due
+	}
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
+}
+
+// applyTaskUpdate returns existing with the fields present in the input
+// changed. Notes and metadata are separate, so each keeps the other's value.
+func applyTaskUpdate(in updateTaskInput, existing Task, loc *time.Location) (Task, error) {
+	t := existing
+	if in.Title != nil {
+		t.Title = strings.TrimSpace(*in.Title)
+	}
+	if in.Notes != nil {
+		t.Notes = *in.Notes
+	}
+	if in.EstimateMinutes != nil {
+		t.Meta.EstimateMinutes = *in.EstimateMinutes
+	}
+	if in.Priority != nil {
+		t.Meta.Priority = *in.Priority
+	}
+	if in.Energy != nil {
+		t.Meta.Energy = *in.Energy
+	}
+	if in.Goal != nil {
+		t.Meta.Goal = strings.TrimSpace(*in.Goal)
+	}
+	if in.Due != nil {
+		due, err := normalizeDue(*in.Due, loc)
+		if err != nil {
+			return Task{}, fmt.Errorf("invalid due: %w", err)
+		}
+		t.Due = parseTaskTime(due)
+	}
+	if in.Status != "" {
+		t.Status = in.Status
+	}
+	return t, nil
+}
+
+type updateTaskInput struct {
+	TaskID     string  `json:"task_id"`
+	Title      *string `json:"title,omitempty"`
+	Notes      *string `json:"notes,omitempty"`
+	Due        *string `json:"due,omitempty"`
+	Status     string  `json:"status,omitempty"`
+	TaskListID string  `json:"task_list_id,omitempty"`
+
+	EstimateMinutes *int    `json:"estimate_minutes,omitempty"`
+	Priority        *int    `json:"priority,omitempty"`
+	Energy          *string `json:"energy,omitempty"`
+	Goal            *string `json:"goal,omitempty"`
+}
+
+func (in updateTaskInput) hasMeta() bool {
+	return in.EstimateMinutes != nil || in.Priority != nil || in.Energy != nil || in.Goal != nil
+}
+
+func parseUpdateTaskInput(raw string) (updateTaskInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return updateTaskInput{}, fmt.Errorf("provide task details as a JSON object in the tool input")
+	}
+
+	var payload updateTaskInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return updateTaskInput{}, fmt.Errorf("invalid update task payload; expected a JSON object: %w", err)
+	}
+
+	payload.TaskID = strings.TrimSpace(payload.TaskID)
+	if payload.TaskID == "" {
+		return updateTaskInput{}, fmt.Errorf("task_id is required to update a task")
+	}
+
+	if payload.Title == nil && payload.Notes == nil && payload.Due == nil && payload.Status == "" && !payload.hasMeta() {
+		return updateTaskInput{}, fmt.Errorf("provide at least one field to update")
+	}
+	if (payload.EstimateMinutes != nil && *payload.EstimateMinutes < 0) || (payload.Priority != nil && *payload.Priority < 0) {
+		return updateTaskInput{}, fmt.Errorf("estimate_minutes and priority must not be negative")
+	}
+	if payload.Energy != nil {
+		energy, err := taskmeta.ParseEnergy(*payload.Energy)
+		if err != nil {
+			return updateTaskInput{}, err
+		}
+		payload.Energy = &energy
+	}
+	if payload.Title != nil && strings.TrimSpace(*payload.Title) == "" {
+		return updateTaskInput{}, fmt.Errorf("title cannot be empty when provided")
+	}
+	if payload.Status != "" && payload.Status != "needsAction" && payload.Status != "completed" {
+		return updateTaskInput{}, fmt.Errorf("status must be needsAction or completed when provided")
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,153 @@
+package undo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"groundhog/internal/oplog"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// UndoLastChange reverts the most recent calendar or task change, or every
+// change made in the most recent agent turn.
+type UndoLastChange struct {
+	log *oplog.Log
+}
+
+var _ tools.Tool = &UndoLastChange{}
+
+func NewUndoLastChange(log *oplog.Log) *UndoLastChange {
+	return &UndoLastChange{
+		log: log,
+	}
+}
+
+func (u *UndoLastChange) Name() string {
+	return "undo_last_change"
+}
+
+func (u *UndoLastChange) Description() string {
+	return `Undo the most recent change made to the user's calendar or tasks.
+
+Input is a stringified JSON object like {"scope": "turn"}.
+
+Fields:
+- scope (string, optional): "change" (default) reverts the single latest change; "turn" reverts every change made while answering the request that made it.`
+}
+
+func (u *UndoLastChange) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"scope": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"change", "turn"},
+				"description": "\"change\" to undo the latest change, \"turn\" to undo everything from the latest request.",
+			},
+		},
+		"required": []string{},
+	}
+}
+
+func (u *UndoLastChange) Call(ctx context.Context, input string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	ops, err := u.pending(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	if len(ops) == 0 {
+		return "There is nothing to undo.", nil
+	}
+
+	reverted, err := u.log.Undo(ctx, ops)
+	if err != nil {
+		if len(reverted) == 0 {
+			return "", err
+		}
+		return fmt.Sprintf("%s\nStopped: %v", FormatReverted(reverted), err), nil
+	}
+	return FormatReverted(reverted), nil
+}
+
+// Describe lists what the call would revert.
+func (u *UndoLastChange) Describe(ctx context.Context, input string) (string, error) {
+	ops, err := u.pending(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	if len(ops) == 0 {
+		return "", fmt.Errorf("there is nothing to undo")
+	}
+	summaries := make([]string, 0, len(ops))
+	for _, op := range ops {
+		summaries = append(summaries, op.Summary)
+	}
+	return "Undo: " + strings.Join(summaries, "; "), nil
+}
+
+// Resolve pins the operations the call reverts, so applying a proposed undo
+// reverts what was previewed even when changes were made in between.
+func (u *UndoLastChange) Resolve(ctx context.Context, input string) (string, error) {
+	payload, err := parseUndoInput(input)
+	if err != nil {
+		return "", err
+	}
+	if len(payload.IDs) == 0 {
+		for _, op := range u.log.Last(ctx, payload.Scope == "turn") {
+			payload.IDs = append(payload.IDs, op.ID)
+		}
+	}
+	resolved, err := json.Marshal(payload)
+	return string(resolved), err
+}
+
+func (u *UndoLastChange) pending(ctx context.Context, input string) ([]oplog.Operation, error) {
+	payload, err := parseUndoInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload.IDs) > 0 {
+		return u.log.Get(ctx, payload.IDs)
+	}
+	return u.log.Last(ctx, payload.Scope == "turn"), nil
+}
+
+type undoInput struct {
+	Scope string `json:"scope,omitempty"`
+	// IDs are set by Resolve.
+	IDs []string `json:"ids,omitempty"`
+}
+
+func parseUndoInput(raw string) (undoInput, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return undoInput{}, nil
+	}
+
+	var payload undoInput
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return undoInput{}, fmt.Errorf("invalid undo payload; expected a JSON object: %w", err)
+	}
+	switch payload.Scope {
+	case "", "change", "turn":
+	default:
+		return undoInput{}, fmt.Errorf("scope must be \"change\" or \"turn\"")
+	}
+	return payload, nil
+}
+
+// FormatReverted describes reverted operations for the user.
+func FormatReverted(ops []oplog.Operation) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Undid %d change(s):\n", len(ops)))
+	for _, op := range ops {
+		b.WriteString(fmt.Sprintf("- %s\n", op.Summary))
+	}
+	return b.String()
+}